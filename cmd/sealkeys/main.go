@@ -0,0 +1,36 @@
+// Command sealkeys prints a fresh encryption key and signing key pair in the
+// format of the MESSAGE_* settings. The key id defaults to today's date:
+//
+//	go run ./cmd/sealkeys -id 2025-11
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func main() {
+	id := flag.String("id", time.Now().Format("2006-01-02"), "key id")
+	flag.Parse()
+
+	encKey := make([]byte, 32)
+	if _, err := rand.Read(encKey); err != nil {
+		fmt.Fprintln(os.Stderr, "sealkeys:", err)
+		os.Exit(1)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "sealkeys:", err)
+		os.Exit(1)
+	}
+
+	b64 := base64.StdEncoding.EncodeToString
+	fmt.Printf("# Add to MESSAGE_ENCRYPTION_KEYS on every producer and consumer\n%s:%s\n\n", *id, b64(encKey))
+	fmt.Printf("# MESSAGE_SIGNING_KEY on the producer (keep secret)\n%s:%s\n\n", *id, b64(priv.Seed()))
+	fmt.Printf("# Add to MESSAGE_VERIFY_KEYS on consumers that do not sign\n%s:%s\n", *id, b64(pub))
+}
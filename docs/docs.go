@@ -19,9 +19,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
+        "/accounts/{accountNumber}/activate": {
             "post": {
-                "description": "Login user",
+                "description": "Lift a freeze or block and return the account to active. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -29,17 +29,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Accounts"
                 ],
-                "summary": "Login user",
+                "summary": "Reactivate an account",
                 "parameters": [
                     {
-                        "description": "Login data",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "accountNumber",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the status change",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserAuthRequest"
+                            "$ref": "#/definitions/dto.AccountStatusRequest"
                         }
                     }
                 ],
@@ -47,55 +54,87 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserAuthResponse"
+                            "$ref": "#/definitions/dto.AccountStatusResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/accounts/{accountNumber}/balance": {
+            "get": {
+                "description": "Get the balance of one of your accounts at the given time. A plain date (YYYY-MM-DD) returns the end-of-day balance. Defaults to now.",
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Logout user by invalidating JWT (client-side)",
                 "tags": [
-                    "Auth"
+                    "Accounts"
+                ],
+                "summary": "Get account balance at a point in time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "accountNumber",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC3339 timestamp or YYYY-MM-DD",
+                        "name": "at",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Logout user",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/dto.BalanceAtResponse"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
-                        }
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/auth/register": {
+        "/accounts/{accountNumber}/block": {
             "post": {
-                "description": "Add a new user to the database",
+                "description": "Block an account so no debits or credits are allowed. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -103,39 +142,60 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Accounts"
                 ],
-                "summary": "Register a new user",
+                "summary": "Block an account",
                 "parameters": [
                     {
-                        "description": "Register data",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "accountNumber",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the status change",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.RegisterRequest"
+                            "$ref": "#/definitions/dto.AccountStatusRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.RegisterResponse"
+                            "$ref": "#/definitions/dto.AccountStatusResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/payments/topup": {
+        "/accounts/{accountNumber}/close": {
             "post": {
-                "description": "Create a new top-up transaction and return a pending transaction id",
+                "description": "Close an account permanently. The balance must be zero. Admins only.",
                 "consumes": [
                     "application/json"
                 ],
@@ -143,61 +203,173 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Payment"
+                    "Accounts"
                 ],
-                "summary": "Create a top-up transaction",
+                "summary": "Close an account",
                 "parameters": [
                     {
-                        "description": "TopUp request payload",
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "accountNumber",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the status change",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.TopUpRequest"
+                            "$ref": "#/definitions/dto.AccountStatusRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "202": {
-                        "description": "transaction accepted"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountStatusResponse"
+                        }
                     },
                     "400": {
-                        "description": "bad request"
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
                     },
                     "500": {
-                        "description": "internal server error"
+                        "description": "Internal Server Error"
                     }
-                }
-            }
-        },
-        "/profile": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
+                ]
+            }
+        },
+        "/accounts/{accountNumber}/freeze": {
+            "post": {
+                "description": "Freeze an account so no debits are allowed. Credits are still accepted. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Get user profile",
                 "tags": [
-                    "Middleware Test"
+                    "Accounts"
+                ],
+                "summary": "Freeze an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account number",
+                        "name": "accountNumber",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason for the status change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountStatusRequest"
+                        }
+                    }
                 ],
-                "summary": "Get user profile with middleware",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AccountStatusResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/users": {
+        "/admin/dead-letters/{queue}": {
             "get": {
-                "description": "Get all users from database",
+                "description": "List parked messages with their decoded body and failure headers. Sealed bodies are only decrypted for DLQ_DECRYPT_EMAILS. Messages stay in the queue.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Inspect a dead-letter queue",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Queue name (e.g. topup)",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum messages to return (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeadLetterListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "503": {
+                        "description": "Service Unavailable"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/dead-letters/{queue}/purge": {
+            "post": {
+                "description": "Drop the selected messages, or the whole queue when all is true",
                 "consumes": [
                     "application/json"
                 ],
@@ -205,26 +377,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
+                ],
+                "summary": "Purge dead-lettered messages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Queue name (e.g. topup)",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Messages to purge",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PurgeRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all users",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.UserListResponse"
-                            }
+                            "$ref": "#/definitions/dto.DeadLetterActionResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "503": {
+                        "description": "Service Unavailable"
                     }
-                }
-            },
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/dead-letters/{queue}/replay": {
             "post": {
-                "description": "Add a new user to the database",
+                "description": "Republish the selected messages to their original exchange with a fresh retry budget",
                 "consumes": [
                     "application/json"
                 ],
@@ -232,163 +435,1523 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Admin"
                 ],
-                "summary": "Create a new user",
+                "summary": "Replay dead-lettered messages",
                 "parameters": [
                     {
-                        "description": "User data",
-                        "name": "user",
+                        "type": "string",
+                        "description": "Queue name (e.g. topup)",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message ids to replay",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserCreateRequest"
+                            "$ref": "#/definitions/dto.ReplayRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserCreateResponse"
+                            "$ref": "#/definitions/dto.DeadLetterActionResponse"
                         }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "503": {
+                        "description": "Service Unavailable"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/dead-letters/{queue}/{messageId}/replay": {
+            "post": {
+                "description": "Replace the message body and republish it. The transaction id cannot change, and the worker parks the message again if its account or amount differ from the transaction record. Sealed messages need DLQ_DECRYPT_EMAILS and are sealed again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Edit and replay a dead-lettered message",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Queue name (e.g. topup)",
+                        "name": "queue",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Message id",
+                        "name": "messageId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Corrected message",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.EditReplayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.DeadLetterActionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "503": {
+                        "description": "Service Unavailable"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserAuthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserAuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Logout user by invalidating JWT (client-side)",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Add a new user to the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "Register data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/health/live": {
+            "get": {
+                "description": "Report that the process is up",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health/ready": {
+            "get": {
+                "description": "Report whether the message broker connection is up and its topology declared",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/topup": {
+            "post": {
+                "description": "Create a new top-up transaction and return a pending transaction id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payment"
+                ],
+                "summary": "Create a top-up transaction",
+                "parameters": [
+                    {
+                        "description": "TopUp request payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TopUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "transaction accepted"
+                    },
+                    "400": {
+                        "description": "bad request"
+                    },
+                    "422": {
+                        "description": "account does not accept top-ups"
+                    },
+                    "429": {
+                        "description": "top-up queue backlogged, see Retry-After"
+                    },
+                    "500": {
+                        "description": "internal server error"
+                    },
+                    "503": {
+                        "description": "top-up queue unavailable or no worker consuming it"
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "get": {
+                "description": "Get user profile",
+                "tags": [
+                    "Middleware Test"
+                ],
+                "summary": "Get user profile with middleware",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/qris/generate": {
+            "post": {
+                "description": "Generate an EMVCo merchant-presented QR payload for one of the user's accounts. Static when amount is omitted, dynamic otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QRIS"
+                ],
+                "summary": "Generate a merchant QR payload",
+                "parameters": [
+                    {
+                        "description": "Merchant data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenerateQRRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GenerateQRResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/qris/parse": {
+            "post": {
+                "description": "Verify the CRC and decode an EMVCo merchant-presented QR payload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QRIS"
+                ],
+                "summary": "Parse a QR payload",
+                "parameters": [
+                    {
+                        "description": "Scanned payload",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ParseQRRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ParseQRResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    }
+                }
+            }
+        },
+        "/qris/pay": {
+            "post": {
+                "description": "Pay the merchant in a scanned QR payload from one of the user's accounts. A dynamic QR must match the one issued and can be paid only once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "QRIS"
+                ],
+                "summary": "Pay by QR",
+                "parameters": [
+                    {
+                        "description": "Scanned payload and payer account",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.PayQRRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.PayQRResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "dynamic QR already paid"
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/qris/png": {
+            "get": {
+                "description": "Render a valid QR payload as a PNG image",
+                "produces": [
+                    "image/png"
+                ],
+                "tags": [
+                    "QRIS"
+                ],
+                "summary": "Render a QR payload as PNG",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "QR payload",
+                        "name": "payload",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 256,
+                        "description": "Image size in pixels (128-1024)",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/transactions/events": {
+            "get": {
+                "description": "Server-Sent Events stream of every transaction status change on accounts owned by the authenticated user",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Stream status changes for all of the user's accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransactionStatusEvent"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/transactions/{transactionId}/events": {
+            "get": {
+                "description": "Server-Sent Events stream. Sends the current status first, then every change, and closes once the transaction is final.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Transactions"
+                ],
+                "summary": "Stream status changes of a transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "transactionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.TransactionStatusEvent"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Get all users from database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get all users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.UserListResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new user to the database",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "description": "User data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Get detail information of a specific user by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user detail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "put": {
+                "description": "Update user data by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated user data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserUpdateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a user from the database by ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Delete a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/virtual-accounts": {
+            "get": {
+                "description": "List the virtual accounts of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Virtual Accounts"
+                ],
+                "summary": "List virtual accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.VAResponse"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Issue (or reactivate) the user's virtual account number for a bank, mapped to one of the user's wallet accounts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Virtual Accounts"
+                ],
+                "summary": "Issue a virtual account",
+                "parameters": [
+                    {
+                        "description": "Wallet account and bank",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.IssueVARequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VAResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/virtual-accounts/callbacks/credit": {
+            "post": {
+                "description": "Callback for banks to notify an incoming transfer to a virtual account. Idempotent on bankReference.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Virtual Accounts"
+                ],
+                "summary": "Bank credit notification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Shared callback secret",
+                        "name": "X-Callback-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Credit notification",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreditNotification"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreditResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "429": {
+                        "description": "top-up queue backlogged, see Retry-After"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "no worker consuming top-ups, see Retry-After"
+                    }
+                }
+            }
+        },
+        "/virtual-accounts/simulate/credit": {
+            "post": {
+                "description": "Local testing only: simulate a bank transfer into a virtual account. Enabled with VA_SIMULATOR=true. Admins only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Virtual Accounts"
+                ],
+                "summary": "Simulate a bank credit",
+                "parameters": [
+                    {
+                        "description": "Simulated transfer",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SimulateCreditRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreditResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "429": {
+                        "description": "top-up queue backlogged, see Retry-After"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    },
+                    "503": {
+                        "description": "no worker consuming top-ups, see Retry-After"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/virtual-accounts/{vaNumber}": {
+            "get": {
+                "description": "Get the wallet mapping of a virtual account number",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Virtual Accounts"
+                ],
+                "summary": "Look up a virtual account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Virtual account number",
+                        "name": "vaNumber",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VAResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Deactivate a virtual account. Incoming credits to it are refused until it is issued again.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Virtual Accounts"
+                ],
+                "summary": "Deactivate a virtual account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Virtual account number",
+                        "name": "vaNumber",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.VAResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "409": {
+                        "description": "Conflict"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "contract.TopUpCreated": {
+            "type": "object",
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "reference": {
+                    "type": "string"
+                },
+                "transactionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AccountStatusRequest": {
+            "type": "object",
+            "required": [
+                "reasonCode"
+            ],
+            "properties": {
+                "note": {
+                    "type": "string"
+                },
+                "reasonCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.AccountStatusResponse": {
+            "type": "object",
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "previousStatus": {
+                    "type": "string"
+                },
+                "reasonCode": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "statusChangedAt": {
+                    "type": "string"
+                },
+                "statusChangedBy": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.BalanceAtResponse": {
+            "type": "object",
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "balance": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "snapshotDate": {
+                    "type": "string"
+                },
+                "transactionsApplied": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CreditNotification": {
+            "type": "object",
+            "required": [
+                "amount",
+                "bankReference",
+                "vaNumber"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "bankReference": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "paidAt": {
+                    "type": "string"
+                },
+                "vaNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CreditResponse": {
+            "type": "object",
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "bankReference": {
+                    "type": "string"
+                },
+                "duplicate": {
+                    "type": "boolean"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transactionId": {
+                    "type": "string"
+                },
+                "vaNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeadLetterActionResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "messageIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "queue": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeadLetterListResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.DeadLetterMessage"
+                    }
+                },
+                "queue": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.DeadLetterMessage": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "decodeError": {
+                    "type": "string"
+                },
+                "exchange": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "object"
+                },
+                "message": {
+                    "$ref": "#/definitions/contract.TopUpCreated"
+                },
+                "messageId": {
+                    "type": "string"
+                },
+                "publishedAt": {
+                    "type": "string"
+                },
+                "rawBody": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "routingKey": {
+                    "type": "string"
+                },
+                "sealed": {
+                    "description": "encrypted or signed on the bus",
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "schema version after upcasting",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.EditReplayRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "$ref": "#/definitions/contract.TopUpCreated"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.GenerateQRRequest": {
+            "type": "object",
+            "required": [
+                "accountNumber",
+                "merchantCity",
+                "merchantName"
+            ],
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "set for a dynamic QR",
+                    "type": "integer"
+                },
+                "billNumber": {
+                    "type": "string",
+                    "maxLength": 25
+                },
+                "merchantCategoryCode": {
+                    "type": "string"
+                },
+                "merchantCity": {
+                    "type": "string",
+                    "maxLength": 15
+                },
+                "merchantName": {
+                    "type": "string",
+                    "maxLength": 25
+                },
+                "postalCode": {
+                    "type": "string",
+                    "maxLength": 10
+                },
+                "terminalLabel": {
+                    "type": "string",
+                    "maxLength": 25
+                }
+            }
+        },
+        "dto.GenerateQRResponse": {
+            "type": "object",
+            "properties": {
+                "dynamic": {
+                    "type": "boolean"
+                },
+                "payload": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.IssueVARequest": {
+            "type": "object",
+            "required": [
+                "accountNumber",
+                "bankCode"
+            ],
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "bankCode": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ParseQRRequest": {
+            "type": "object",
+            "required": [
+                "payload"
+            ],
+            "properties": {
+                "payload": {
+                    "type": "string"
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
-                "description": "Get detail information of a specific user by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Get user detail",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserDetailResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request"
-                    },
-                    "404": {
-                        "description": "Not Found"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
+        "dto.ParseQRResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string"
+                },
+                "billNumber": {
+                    "type": "string"
+                },
+                "countryCode": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "dynamic": {
+                    "type": "boolean"
+                },
+                "merchantAccountNumber": {
+                    "type": "string"
+                },
+                "merchantAccounts": {
+                    "description": "keyed by tag",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/qris.MerchantAccount"
                     }
+                },
+                "merchantCategoryCode": {
+                    "type": "string"
+                },
+                "merchantCity": {
+                    "type": "string"
+                },
+                "merchantName": {
+                    "type": "string"
+                },
+                "pointOfInitiation": {
+                    "type": "string"
+                },
+                "postalCode": {
+                    "type": "string"
+                },
+                "referenceLabel": {
+                    "type": "string"
+                },
+                "terminalLabel": {
+                    "type": "string"
                 }
-            },
-            "put": {
-                "description": "Update user data by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Update user",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Updated user data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserUpdateRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserUpdateResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request"
-                    },
-                    "404": {
-                        "description": "Not Found"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
-                    }
+            }
+        },
+        "dto.PayQRRequest": {
+            "type": "object",
+            "required": [
+                "accountNumber",
+                "payload"
+            ],
+            "properties": {
+                "accountNumber": {
+                    "description": "payer",
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "required for a static QR",
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "string"
                 }
-            },
-            "delete": {
-                "description": "Delete a user from the database by ID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Delete a user",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request"
-                    },
-                    "404": {
-                        "description": "Not Found"
-                    },
-                    "500": {
-                        "description": "Internal Server Error"
+            }
+        },
+        "dto.PayQRResponse": {
+            "type": "object",
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "merchantAccountNumber": {
+                    "type": "string"
+                },
+                "merchantName": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transactionId": {
+                    "type": "string"
+                }
+            }
+        },
+        "dto.PurgeRequest": {
+            "type": "object",
+            "properties": {
+                "all": {
+                    "type": "boolean"
+                },
+                "messageIds": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
         "dto.RegisterRequest": {
             "type": "object",
             "required": [
@@ -422,6 +1985,36 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.ReplayRequest": {
+            "type": "object",
+            "required": [
+                "messageIds"
+            ],
+            "properties": {
+                "messageIds": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "dto.SimulateCreditRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "vaNumber"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "vaNumber": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.TopUpRequest": {
             "type": "object",
             "required": [
@@ -433,6 +2026,31 @@ const docTemplate = `{
                 },
                 "amount": {
                     "type": "integer"
+                },
+                "reference": {
+                    "description": "external reference, e.g. a bank transfer id",
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "dto.TransactionStatusEvent": {
+            "type": "object",
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "occurredAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transactionId": {
+                    "type": "string"
                 }
             }
         },
@@ -586,6 +2204,46 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "dto.VAResponse": {
+            "type": "object",
+            "properties": {
+                "accountNumber": {
+                    "type": "string"
+                },
+                "bankCode": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deactivatedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "vaNumber": {
+                    "type": "string"
+                }
+            }
+        },
+        "qris.MerchantAccount": {
+            "type": "object",
+            "properties": {
+                "criteria": {
+                    "type": "string"
+                },
+                "guid": {
+                    "type": "string"
+                },
+                "merchantId": {
+                    "type": "string"
+                },
+                "pan": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
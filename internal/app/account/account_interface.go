@@ -0,0 +1,10 @@
+package account
+
+import "github.com/junicochandra/golang-api-service/internal/app/account/dto"
+
+type AccountUseCase interface {
+	Freeze(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error)
+	Block(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error)
+	Close(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error)
+	Activate(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error)
+}
@@ -0,0 +1,145 @@
+package account
+
+import (
+	"errors"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/account/dto"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotFound          = errors.New("Account not found")
+	ErrInvalidReasonCode = errors.New("Invalid reason code")
+	ErrActorRequired     = errors.New("Actor is required")
+	ErrInvalidTransition = errors.New("Account status transition not allowed")
+	ErrNonZeroBalance    = errors.New("Account balance must be zero to close")
+)
+
+// Reason codes accepted for any status change
+var reasonCodes = map[string]bool{
+	"fraud_suspected":   true,
+	"court_order":       true,
+	"kyc_incomplete":    true,
+	"compliance_review": true,
+	"customer_request":  true,
+	"dormant":           true,
+	"deceased":          true,
+	"review_cleared":    true,
+}
+
+// Statuses an account may move from, keyed by target status
+var allowedFrom = map[string][]string{
+	entity.AccountStatusFrozen:  {entity.AccountStatusActive},
+	entity.AccountStatusBlocked: {entity.AccountStatusActive, entity.AccountStatusFrozen},
+	entity.AccountStatusClosed:  {entity.AccountStatusActive, entity.AccountStatusFrozen, entity.AccountStatusBlocked},
+	entity.AccountStatusActive:  {entity.AccountStatusFrozen, entity.AccountStatusBlocked},
+}
+
+type accountUseCase struct {
+	accountRepo repository.AccountRepository
+}
+
+func NewAccountUseCase(accountRepo repository.AccountRepository) AccountUseCase {
+	return &accountUseCase{accountRepo: accountRepo}
+}
+
+func (u *accountUseCase) Freeze(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error) {
+	return u.changeStatus(accountNumber, entity.AccountStatusFrozen, req, actor)
+}
+
+func (u *accountUseCase) Block(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error) {
+	return u.changeStatus(accountNumber, entity.AccountStatusBlocked, req, actor)
+}
+
+func (u *accountUseCase) Close(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error) {
+	return u.changeStatus(accountNumber, entity.AccountStatusClosed, req, actor)
+}
+
+func (u *accountUseCase) Activate(accountNumber string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error) {
+	return u.changeStatus(accountNumber, entity.AccountStatusActive, req, actor)
+}
+
+func (u *accountUseCase) changeStatus(accountNumber, target string, req *dto.AccountStatusRequest, actor string) (*dto.AccountStatusResponse, error) {
+	if req == nil || !reasonCodes[req.ReasonCode] {
+		return nil, ErrInvalidReasonCode
+	}
+	if actor == "" {
+		return nil, ErrActorRequired
+	}
+
+	account, err := u.accountRepo.GetByAccountNumber(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrNotFound
+	}
+
+	current := account.Status
+	if current == "" {
+		current = entity.AccountStatusActive
+	}
+	if !canTransition(current, target) {
+		return nil, ErrInvalidTransition
+	}
+	if target == entity.AccountStatusClosed && !account.Balance.Equal(decimal.Zero) {
+		return nil, ErrNonZeroBalance
+	}
+
+	now := time.Now()
+	reason := req.ReasonCode
+	account.Status = target
+	account.StatusReason = &reason
+	account.StatusChangedAt = &now
+	account.StatusChangedBy = &actor
+	account.UpdatedAt = now
+
+	log := &entity.AccountStatusLog{
+		AccountNumber: account.AccountNumber,
+		FromStatus:    current,
+		ToStatus:      target,
+		ReasonCode:    req.ReasonCode,
+		Actor:         actor,
+		CreatedAt:     now,
+	}
+	if req.Note != "" {
+		note := req.Note
+		log.Note = &note
+	}
+
+	// The repository re-checks status and balance under a row lock, since a
+	// credit or another status change may have landed since the read above
+	if err := u.accountRepo.UpdateStatusTx(account, log); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAccountUnavailable):
+			return nil, ErrNotFound
+		case errors.Is(err, repository.ErrStatusChanged):
+			return nil, ErrInvalidTransition
+		case errors.Is(err, repository.ErrBalanceNotZero):
+			return nil, ErrNonZeroBalance
+		}
+		return nil, err
+	}
+
+	return &dto.AccountStatusResponse{
+		AccountNumber:   account.AccountNumber,
+		Balance:         account.Balance,
+		Status:          target,
+		PreviousStatus:  current,
+		ReasonCode:      req.ReasonCode,
+		StatusChangedBy: actor,
+		StatusChangedAt: now,
+	}, nil
+}
+
+func canTransition(from, to string) bool {
+	for _, s := range allowedFrom[to] {
+		if s == from {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type AccountStatusRequest struct {
+	ReasonCode string `json:"reasonCode" binding:"required"`
+	Note       string `json:"note"`
+}
+
+type AccountStatusResponse struct {
+	AccountNumber   string          `json:"accountNumber"`
+	Balance         decimal.Decimal `json:"balance"`
+	Status          string          `json:"status"`
+	PreviousStatus  string          `json:"previousStatus"`
+	ReasonCode      string          `json:"reasonCode"`
+	StatusChangedBy string          `json:"statusChangedBy"`
+	StatusChangedAt time.Time       `json:"statusChangedAt"`
+}
@@ -3,8 +3,12 @@ package auth
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/junicochandra/golang-api-service/internal/app/auth/dto"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
 	"github.com/junicochandra/golang-api-service/internal/domain/entity"
 	"github.com/junicochandra/golang-api-service/internal/domain/repository"
 	"github.com/junicochandra/golang-api-service/internal/infrastructure/service"
@@ -17,10 +21,11 @@ var (
 
 type authUseCase struct {
 	userRepo repository.UserRepository
+	events   messaging.EventPublisher
 }
 
-func NewAuthUseCase(userRepo repository.UserRepository) AuthUseCase {
-	return &authUseCase{userRepo: userRepo}
+func NewAuthUseCase(userRepo repository.UserRepository, events messaging.EventPublisher) AuthUseCase {
+	return &authUseCase{userRepo: userRepo, events: events}
 }
 
 func (u *authUseCase) Register(req *dto.RegisterRequest) error {
@@ -46,7 +51,17 @@ func (u *authUseCase) Register(req *dto.RegisterRequest) error {
 		Password: string(hashed),
 	}
 
-	return u.userRepo.Create(user)
+	if err := u.userRepo.Create(user); err != nil {
+		return err
+	}
+
+	u.events.Publish(contract.TypeUserRegistered, &contract.UserRegistered{
+		UserID:       user.ID,
+		Name:         user.Name,
+		Email:        user.Email,
+		RegisteredAt: time.Now(),
+	}, strconv.FormatUint(user.ID, 10))
+	return nil
 }
 
 func (u *authUseCase) Login(req *dto.UserAuthRequest) (string, error) {
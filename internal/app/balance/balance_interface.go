@@ -0,0 +1,12 @@
+package balance
+
+import (
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/balance/dto"
+)
+
+type BalanceUseCase interface {
+	GetBalanceAt(accountNumber string, at time.Time, email string) (*dto.BalanceAtResponse, error)
+	TakeDailySnapshot(day time.Time) (*dto.SnapshotResult, error)
+}
@@ -0,0 +1,161 @@
+package balance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/balance/dto"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrNotFound      = errors.New("Account not found")
+	ErrUserNotFound  = errors.New("User not found")
+	ErrForbidden     = errors.New("Account does not belong to this user")
+	ErrFutureTime    = errors.New("Balance time cannot be in the future")
+	ErrIncompleteDay = errors.New("Snapshot day has not ended yet")
+)
+
+type balanceUseCase struct {
+	userRepo        repository.UserRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	snapshotRepo    repository.BalanceSnapshotRepository
+}
+
+func NewBalanceUseCase(userRepo repository.UserRepository, accountRepo repository.AccountRepository, transactionRepo repository.TransactionRepository, snapshotRepo repository.BalanceSnapshotRepository) BalanceUseCase {
+	return &balanceUseCase{
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		snapshotRepo:    snapshotRepo,
+	}
+}
+
+// GetBalanceAt rolls the nearest snapshot at or before `at` forward with the
+// transactions settled after it. Without a snapshot it rolls the current
+// balance backwards instead. Only the account's owner may read it.
+func (u *balanceUseCase) GetBalanceAt(accountNumber string, at time.Time, email string) (*dto.BalanceAtResponse, error) {
+	now := time.Now()
+	if at.After(now) {
+		return nil, ErrFutureTime
+	}
+
+	account, err := u.accountRepo.GetByAccountNumber(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrNotFound
+	}
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if account.UserID != user.ID {
+		return nil, ErrForbidden
+	}
+
+	res := &dto.BalanceAtResponse{
+		AccountNumber: accountNumber,
+		At:            at,
+		Currency:      account.Currency,
+	}
+
+	snapshot, err := u.snapshotRepo.GetLatestAtOrBefore(accountNumber, at)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot != nil {
+		txns, err := u.transactionRepo.ListSettledByAccountBetween(accountNumber, snapshot.AsOf, at)
+		if err != nil {
+			return nil, err
+		}
+		res.Balance = snapshot.Balance.Add(netChange(accountNumber, txns))
+		res.SnapshotDate = snapshot.SnapshotDate
+		res.TransactionsApplied = len(txns)
+		return res, nil
+	}
+
+	balance, applied, err := u.rollBack(account, at, now)
+	if err != nil {
+		return nil, err
+	}
+	res.Balance = balance
+	res.TransactionsApplied = applied
+	return res, nil
+}
+
+// TakeDailySnapshot stores the end-of-day balance of every account for the given day
+func (u *balanceUseCase) TakeDailySnapshot(day time.Time) (*dto.SnapshotResult, error) {
+	now := time.Now()
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	endOfDay := start.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	if endOfDay.After(now) {
+		return nil, ErrIncompleteDay
+	}
+
+	accounts, err := u.accountRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.SnapshotResult{SnapshotDate: start.Format("2006-01-02")}
+	var firstErr error
+	for i := range accounts {
+		account := &accounts[i]
+
+		balance, _, err := u.rollBack(account, endOfDay, now)
+		if err == nil {
+			err = u.snapshotRepo.Upsert(&entity.BalanceSnapshot{
+				AccountNumber: account.AccountNumber,
+				SnapshotDate:  result.SnapshotDate,
+				AsOf:          endOfDay,
+				Balance:       balance,
+				Currency:      account.Currency,
+				CreatedAt:     now,
+			})
+		}
+		if err != nil {
+			result.Failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("snapshot %s: %w", account.AccountNumber, err)
+			}
+			continue
+		}
+		result.Accounts++
+	}
+
+	return result, firstErr
+}
+
+// rollBack derives the balance at `at` from the live balance read at `now`
+func (u *balanceUseCase) rollBack(account *entity.Account, at, now time.Time) (decimal.Decimal, int, error) {
+	txns, err := u.transactionRepo.ListSettledByAccountBetween(account.AccountNumber, at, now)
+	if err != nil {
+		return decimal.Zero, 0, err
+	}
+	return account.Balance.Sub(netChange(account.AccountNumber, txns)), len(txns), nil
+}
+
+// netChange sums credits minus debits for the account. A top-up names the
+// account as both sender and receiver and counts as a credit only.
+func netChange(accountNumber string, txns []entity.Transaction) decimal.Decimal {
+	net := decimal.Zero
+	for _, t := range txns {
+		if t.ReceiverAccountID == accountNumber {
+			net = net.Add(t.Amount)
+		}
+		if t.SenderAccountID == accountNumber && t.ReceiverAccountID != accountNumber {
+			net = net.Sub(t.Amount)
+		}
+	}
+	return net
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type BalanceAtResponse struct {
+	AccountNumber       string          `json:"accountNumber"`
+	At                  time.Time       `json:"at"`
+	Balance             decimal.Decimal `json:"balance"`
+	Currency            string          `json:"currency"`
+	SnapshotDate        string          `json:"snapshotDate,omitempty"`
+	TransactionsApplied int             `json:"transactionsApplied"`
+}
+
+type SnapshotResult struct {
+	SnapshotDate string `json:"snapshotDate"`
+	Accounts     int    `json:"accounts"`
+	Failed       int    `json:"failed"`
+}
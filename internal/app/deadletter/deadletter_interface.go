@@ -0,0 +1,10 @@
+package deadletter
+
+import "github.com/junicochandra/golang-api-service/internal/app/deadletter/dto"
+
+type DeadLetterUseCase interface {
+	List(queue string, limit int, actor string) (*dto.DeadLetterListResponse, error)
+	Replay(queue string, req *dto.ReplayRequest, actor string) (*dto.DeadLetterActionResponse, error)
+	EditAndReplay(queue, messageID string, req *dto.EditReplayRequest, actor string) (*dto.DeadLetterActionResponse, error)
+	Purge(queue string, req *dto.PurgeRequest, actor string) (*dto.DeadLetterActionResponse, error)
+}
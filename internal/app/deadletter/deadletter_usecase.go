@@ -0,0 +1,339 @@
+package deadletter
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/junicochandra/golang-api-service/internal/app/deadletter/dto"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/seal"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+)
+
+var (
+	ErrUnknownQueue       = errors.New("Unknown dead-letter queue")
+	ErrActorRequired      = errors.New("Actor is required")
+	ErrNothingSelected    = errors.New("Select message ids or set all to purge the queue")
+	ErrMessageNotFound    = errors.New("Message not found in dead-letter queue")
+	ErrTransactionChanged = errors.New("Edited message must keep the original transaction id")
+	ErrInvalidMessage     = errors.New("Edited message needs an account number and a positive amount")
+	ErrQueueUnavailable   = errors.New("Dead-letter queue is unavailable")
+	ErrSealed             = errors.New("Message is sealed and you are not allowed to decrypt it")
+)
+
+const (
+	ActionInspect    = "inspect"
+	ActionReplay     = "replay"
+	ActionEditReplay = "edit_replay"
+	ActionPurge      = "purge"
+
+	defaultListLimit = 100
+)
+
+type deadLetterUseCase struct {
+	port       messaging.DeadLetterPort
+	auditRepo  repository.DeadLetterAuditRepository
+	queues     map[string]string // public name -> parking queue
+	sealer     *seal.Sealer
+	decryptors map[string]bool // lower-cased actor emails
+}
+
+// NewDeadLetterUseCase exposes the parking queues in queues by a short name,
+// e.g. "topup" -> "topup_queue.parking". Sealed messages are opened with
+// sealer, only for the actors in decryptors; others see them as sealed.
+func NewDeadLetterUseCase(port messaging.DeadLetterPort, auditRepo repository.DeadLetterAuditRepository, queues map[string]string, sealer *seal.Sealer, decryptors []string) DeadLetterUseCase {
+	u := &deadLetterUseCase{port: port, auditRepo: auditRepo, queues: queues, sealer: sealer, decryptors: map[string]bool{}}
+	for _, email := range decryptors {
+		if email = strings.TrimSpace(strings.ToLower(email)); email != "" {
+			u.decryptors[email] = true
+		}
+	}
+	return u
+}
+
+func (u *deadLetterUseCase) List(queue string, limit int, actor string) (*dto.DeadLetterListResponse, error) {
+	name, err := u.resolve(queue, actor)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+
+	letters, err := u.port.Peek(name, limit)
+	if err != nil {
+		u.audit(name, ActionInspect, nil, "", actor, err)
+		return nil, unavailable(err)
+	}
+
+	res := &dto.DeadLetterListResponse{Queue: name, Count: len(letters), Messages: make([]dto.DeadLetterMessage, 0, len(letters))}
+	var decrypted []string
+	for _, dl := range letters {
+		msg := u.toMessage(dl, actor)
+		if msg.Sealed && msg.Message != nil {
+			decrypted = append(decrypted, dl.MessageID)
+		}
+		res.Messages = append(res.Messages, msg)
+	}
+
+	detail := ""
+	if len(decrypted) > 0 {
+		detail = "decrypted: " + strings.Join(decrypted, ",")
+	}
+	u.audit(name, ActionInspect, nil, detail, actor, nil)
+	return res, nil
+}
+
+func (u *deadLetterUseCase) Replay(queue string, req *dto.ReplayRequest, actor string) (*dto.DeadLetterActionResponse, error) {
+	name, err := u.resolve(queue, actor)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || len(req.MessageIDs) == 0 {
+		return nil, ErrNothingSelected
+	}
+
+	replayed, err := u.port.Replay(name, req.MessageIDs, nil)
+	u.audit(name, ActionReplay, replayed, "requested: "+strings.Join(req.MessageIDs, ","), actor, err)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	return &dto.DeadLetterActionResponse{Queue: name, Action: ActionReplay, MessageIDs: replayed, Count: len(replayed)}, nil
+}
+
+func (u *deadLetterUseCase) EditAndReplay(queue, messageID string, req *dto.EditReplayRequest, actor string) (*dto.DeadLetterActionResponse, error) {
+	name, err := u.resolve(queue, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := u.find(name, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A sealed original is opened to check it and the edit is sealed again
+	sealed := seal.IsSealed(original.Body)
+	if sealed {
+		if !u.canDecrypt(actor) {
+			return nil, ErrSealed
+		}
+		if original.Body, _, err = u.sealer.Open(original.Body); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSealed, err)
+		}
+	}
+
+	before, _, decodeErr := contract.DecodeTopUpCreated(original.Body)
+	if decodeErr == nil && before.TransactionID != req.Message.TransactionID {
+		return nil, ErrTransactionChanged
+	}
+	if req.Message.AccountNumber == "" || !req.Message.Amount.IsPositive() {
+		return nil, ErrInvalidMessage
+	}
+
+	// Re-encode at the current schema version under the same message id
+	env, err := contract.NewTopUpCreated(&req.Message)
+	if err != nil {
+		return nil, err
+	}
+	env.MessageID = messageID
+	body, err := env.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	detail := "before: " + string(original.Body) + "\nafter: " + string(body)
+	if sealed {
+		// The audit table is not encrypted, so keep sealed payloads out of it
+		detail = sealedEditDetail(original.Body, body, before, &req.Message)
+	}
+	if req.Note != "" {
+		detail += "\nnote: " + req.Note
+	}
+	if sealed {
+		if body, err = u.sealer.Seal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	replayed, err := u.port.Replay(name, []string{messageID}, map[string][]byte{messageID: body})
+	u.audit(name, ActionEditReplay, replayed, detail, actor, err)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+	if len(replayed) == 0 {
+		// Picked up by someone else between find and replay
+		return nil, ErrMessageNotFound
+	}
+
+	return &dto.DeadLetterActionResponse{Queue: name, Action: ActionEditReplay, MessageIDs: replayed, Count: len(replayed)}, nil
+}
+
+func (u *deadLetterUseCase) Purge(queue string, req *dto.PurgeRequest, actor string) (*dto.DeadLetterActionResponse, error) {
+	name, err := u.resolve(queue, actor)
+	if err != nil {
+		return nil, err
+	}
+	// An empty selection purges everything, so require it to be explicit
+	if req == nil || (len(req.MessageIDs) == 0 && !req.All) {
+		return nil, ErrNothingSelected
+	}
+
+	ids := req.MessageIDs
+	if req.All {
+		ids = nil
+	}
+
+	count, err := u.port.Purge(name, ids)
+	detail := ""
+	if req.All {
+		detail = "purged entire queue"
+	}
+	u.audit(name, ActionPurge, ids, detail, actor, err)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+
+	return &dto.DeadLetterActionResponse{Queue: name, Action: ActionPurge, MessageIDs: ids, Count: count}, nil
+}
+
+func (u *deadLetterUseCase) resolve(queue, actor string) (string, error) {
+	if actor == "" {
+		return "", ErrActorRequired
+	}
+	name, ok := u.queues[queue]
+	if !ok {
+		return "", ErrUnknownQueue
+	}
+	return name, nil
+}
+
+func (u *deadLetterUseCase) find(queue, messageID string) (*messaging.DeadLetter, error) {
+	letters, err := u.port.Peek(queue, 0)
+	if err != nil {
+		return nil, unavailable(err)
+	}
+	for i := range letters {
+		if letters[i].MessageID == messageID {
+			return &letters[i], nil
+		}
+	}
+	return nil, ErrMessageNotFound
+}
+
+// audit never fails the operator action; the queue change already happened
+func (u *deadLetterUseCase) audit(queue, action string, ids []string, detail, actor string, actionErr error) {
+	entry := &entity.DeadLetterAuditLog{
+		Queue:      queue,
+		Action:     action,
+		MessageIDs: strings.Join(ids, ","),
+		Result:     "success",
+		Actor:      actor,
+	}
+	if detail != "" {
+		entry.Detail = &detail
+	}
+	if actionErr != nil {
+		msg := actionErr.Error()
+		entry.Result = "failed"
+		entry.Error = &msg
+	}
+	if err := u.auditRepo.Create(entry); err != nil {
+		log.Printf("dead-letter audit write failed (%s %s by %s): %v", action, queue, actor, err)
+	}
+}
+
+func unavailable(err error) error {
+	return fmt.Errorf("%w: %w", ErrQueueUnavailable, err)
+}
+
+// sealedEditDetail records an edit of a sealed message by body hashes and the
+// names of the fields that changed, without their values
+func sealedEditDetail(before, after []byte, from, to *contract.TopUpCreated) string {
+	changed := "unknown (original could not be decoded)"
+	if from != nil {
+		var fields []string
+		if from.AccountNumber != to.AccountNumber {
+			fields = append(fields, "accountNumber")
+		}
+		if !from.Amount.Equal(to.Amount) {
+			fields = append(fields, "amount")
+		}
+		if from.Currency != to.Currency {
+			fields = append(fields, "currency")
+		}
+		if from.Reference != to.Reference {
+			fields = append(fields, "reference")
+		}
+		if !from.CreatedAt.Equal(to.CreatedAt) {
+			fields = append(fields, "createdAt")
+		}
+		changed = strings.Join(fields, ",")
+		if changed == "" {
+			changed = "none"
+		}
+	}
+	return fmt.Sprintf("before: sha256:%x\nafter: sha256:%x\nchanged: %s", sha256.Sum256(before), sha256.Sum256(after), changed)
+}
+
+func (u *deadLetterUseCase) canDecrypt(actor string) bool {
+	return u.sealer != nil && u.decryptors[strings.ToLower(actor)]
+}
+
+// toMessage opens sealed bodies for actors allowed to decrypt them; anyone
+// else gets the message without its body
+func (u *deadLetterUseCase) toMessage(dl messaging.DeadLetter, actor string) dto.DeadLetterMessage {
+	if !seal.IsSealed(dl.Body) {
+		return toMessage(dl)
+	}
+
+	reason := ErrSealed
+	if u.canDecrypt(actor) {
+		body, _, err := u.sealer.Open(dl.Body)
+		if err == nil {
+			dl.Body = body
+			msg := toMessage(dl)
+			msg.Sealed = true
+			return msg
+		}
+		reason = err
+	}
+
+	dl.Body = nil
+	msg := toMessage(dl)
+	msg.Sealed = true
+	msg.RawBody = ""
+	msg.DecodeError = reason.Error()
+	return msg
+}
+
+func toMessage(dl messaging.DeadLetter) dto.DeadLetterMessage {
+	msg := dto.DeadLetterMessage{
+		MessageID:   dl.MessageID,
+		Exchange:    dl.Exchange,
+		RoutingKey:  dl.RoutingKey,
+		Reason:      dl.Reason,
+		Attempts:    dl.Attempts,
+		PublishedAt: dl.Timestamp,
+		Headers:     dl.Headers,
+	}
+
+	body, env, err := contract.DecodeTopUpCreated(dl.Body)
+	if env != nil {
+		msg.Type = env.Type
+		msg.Version = env.Version
+	}
+	if err != nil {
+		msg.RawBody = string(dl.Body)
+		msg.DecodeError = err.Error()
+	} else {
+		msg.Message = body
+	}
+	return msg
+}
@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
+)
+
+type DeadLetterMessage struct {
+	MessageID   string                 `json:"messageId"`
+	Exchange    string                 `json:"exchange"`
+	RoutingKey  string                 `json:"routingKey"`
+	Reason      string                 `json:"reason"`
+	Attempts    int                    `json:"attempts"`
+	PublishedAt time.Time              `json:"publishedAt"`
+	Headers     map[string]interface{} `json:"headers" swaggertype:"object"`
+	Type        string                 `json:"type,omitempty"`
+	Version     int                    `json:"version,omitempty"` // schema version after upcasting
+	Message     *contract.TopUpCreated `json:"message,omitempty"`
+	RawBody     string                 `json:"rawBody,omitempty"`
+	Sealed      bool                   `json:"sealed,omitempty"` // encrypted or signed on the bus
+	DecodeError string                 `json:"decodeError,omitempty"`
+}
+
+type DeadLetterListResponse struct {
+	Queue    string              `json:"queue"`
+	Count    int                 `json:"count"`
+	Messages []DeadLetterMessage `json:"messages"`
+}
+
+type ReplayRequest struct {
+	MessageIDs []string `json:"messageIds" binding:"required,min=1"`
+}
+
+type EditReplayRequest struct {
+	Message contract.TopUpCreated `json:"message" binding:"required"`
+	Note    string                `json:"note"`
+}
+
+type PurgeRequest struct {
+	MessageIDs []string `json:"messageIds"`
+	All        bool     `json:"all"`
+}
+
+type DeadLetterActionResponse struct {
+	Queue      string   `json:"queue"`
+	Action     string   `json:"action"`
+	MessageIDs []string `json:"messageIds,omitempty"`
+	Count      int      `json:"count"`
+}
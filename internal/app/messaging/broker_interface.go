@@ -1,8 +1,73 @@
 package messaging
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Errors adapters return from Publish. Wrapped adapter errors still match
+// these with errors.Is.
+var (
+	ErrNotConnected   = errors.New("messaging: broker not connected")
+	ErrNacked         = errors.New("messaging: message nacked by broker")
+	ErrUnroutable     = errors.New("messaging: message unroutable")
+	ErrConfirmTimeout = errors.New("messaging: publish confirm timed out")
+)
+
+// Message is a broker-neutral message
+type Message struct {
+	ID          string
+	ContentType string
+	Headers     map[string]interface{}
+	Timestamp   time.Time
+	Body        []byte
+	// CorrelationID ties a reply to its request
+	CorrelationID string
+	// ReplyTo names the queue a request's reply should be sent to
+	ReplyTo string
+	// Mandatory fails the publish with ErrUnroutable when nothing is bound
+	// for the routing key
+	Mandatory bool
+}
+
+// Delivery is a message received from a subscription. It must be settled
+// with BrokerPort.Ack or BrokerPort.Nack exactly once.
+type Delivery struct {
+	Message
+	Queue       string
+	RoutingKey  string
+	Redelivered bool
+	// Attempts is how many times the message has been retried so far
+	Attempts int
+	// Handle is the adapter's own delivery; only the adapter reads it
+	Handle interface{}
+}
+
+type SubscribeOptions struct {
+	// Prefetch caps unsettled deliveries on the subscription (default 1)
+	Prefetch int
+}
+
+// Subscription streams deliveries until its context is done or the broker
+// drops it, then closes Deliveries. Close releases the subscription and must
+// only be called once every delivery it produced has been settled.
+type Subscription interface {
+	Deliveries() <-chan Delivery
+	Close() error
+}
+
 type BrokerPort interface {
-	Connect() error
+	// Publish sends msg to exchange and returns once the broker has accepted it
+	Publish(ctx context.Context, exchange, routingKey string, msg Message) error
+	Subscribe(ctx context.Context, queue string, opts SubscribeOptions) (Subscription, error)
+	Ack(d Delivery) error
+	// Nack with requeue asks for the message to be tried again later; the
+	// adapter may delay it or park it once its attempts are used up. Without
+	// requeue the message is dead-lettered straight away.
+	Nack(d Delivery, requeue bool) error
+	// WaitReady blocks until the broker is connected or ctx is done
+	WaitReady(ctx context.Context) error
+	IsReady() bool
 	Close() error
-	Publish(queue string, body []byte) error
-	ConsumeOne(queue string) ([]byte, error)
 }
@@ -0,0 +1,250 @@
+// Package conformance checks that a messaging.BrokerPort adapter behaves the
+// way the worker relies on: mandatory routing, ack, retry with attempt
+// counting, parking, prefetch and redelivery after a dropped subscription.
+// Each adapter's tests run it through Test.
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+// quietPeriod is how long a check waits to be sure nothing else arrives
+const quietPeriod = 300 * time.Millisecond
+
+// Fixture describes an empty queue bound to Exchange/RoutingKey
+type Fixture struct {
+	Broker            messaging.BrokerPort
+	Exchange          string
+	RoutingKey        string
+	UnboundRoutingKey string
+	Queue             string
+	// MaxAttempts is the number of retries the queue allows before parking
+	MaxAttempts int
+	// Redelivery bounds how long a retried or abandoned message takes to
+	// come back (retry delay, ack wait)
+	Redelivery time.Duration
+}
+
+type check struct {
+	name string
+	run  func(f Fixture) error
+}
+
+var checks = []check{
+	{"unroutable mandatory publish fails", checkUnroutable},
+	{"acked message is delivered once", checkAck},
+	{"requeued message comes back with attempts", checkRetry},
+	{"message parks after max attempts", checkParking},
+	{"rejected message is not redelivered", checkReject},
+	{"prefetch holds back further messages", checkPrefetch},
+	{"unacked message returns after close", checkCloseRequeue},
+}
+
+// Test runs every check as a subtest of t. The checks share f's queue, so
+// they run one after another.
+func Test(t *testing.T, f Fixture) {
+	t.Helper()
+	for _, c := range checks {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.run(f); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func checkUnroutable(f Fixture) error {
+	err := f.Broker.Publish(context.Background(), f.Exchange, f.UnboundRoutingKey, messaging.Message{Body: []byte("x"), Mandatory: true})
+	if !errors.Is(err, messaging.ErrUnroutable) {
+		return fmt.Errorf("got %v, want ErrUnroutable", err)
+	}
+	return nil
+}
+
+func checkAck(f Fixture) error {
+	return withSubscription(f, 1, func(sub messaging.Subscription) error {
+		if err := publish(f, "ack"); err != nil {
+			return err
+		}
+		d, err := receive(sub, f.Redelivery)
+		if err != nil {
+			return err
+		}
+		if string(d.Body) != "ack" || d.ID == "" || d.Attempts != 0 || d.Redelivered {
+			return fmt.Errorf("unexpected first delivery: body=%q id=%q attempts=%d redelivered=%v", d.Body, d.ID, d.Attempts, d.Redelivered)
+		}
+		if err := f.Broker.Ack(d); err != nil {
+			return err
+		}
+		return expectNothing(sub, quietPeriod)
+	})
+}
+
+func checkRetry(f Fixture) error {
+	return withSubscription(f, 1, func(sub messaging.Subscription) error {
+		if err := publish(f, "retry"); err != nil {
+			return err
+		}
+		d, err := receive(sub, f.Redelivery)
+		if err != nil {
+			return err
+		}
+		if err := f.Broker.Nack(d, true); err != nil {
+			return err
+		}
+		d, err = receive(sub, f.Redelivery)
+		if err != nil {
+			return fmt.Errorf("no redelivery: %w", err)
+		}
+		if d.Attempts != 1 || !d.Redelivered {
+			return fmt.Errorf("redelivery has attempts=%d redelivered=%v, want 1 and true", d.Attempts, d.Redelivered)
+		}
+		return f.Broker.Ack(d)
+	})
+}
+
+func checkParking(f Fixture) error {
+	return withSubscription(f, 1, func(sub messaging.Subscription) error {
+		if err := publish(f, "park"); err != nil {
+			return err
+		}
+		deliveries := 0
+		for {
+			d, err := receive(sub, f.Redelivery)
+			if err != nil {
+				break
+			}
+			deliveries++
+			if deliveries > f.MaxAttempts+1 {
+				return fmt.Errorf("still delivered after %d attempts", deliveries)
+			}
+			if err := f.Broker.Nack(d, true); err != nil {
+				return err
+			}
+		}
+		if deliveries != f.MaxAttempts+1 {
+			return fmt.Errorf("delivered %d times, want %d", deliveries, f.MaxAttempts+1)
+		}
+		return nil
+	})
+}
+
+func checkReject(f Fixture) error {
+	return withSubscription(f, 1, func(sub messaging.Subscription) error {
+		if err := publish(f, "reject"); err != nil {
+			return err
+		}
+		d, err := receive(sub, f.Redelivery)
+		if err != nil {
+			return err
+		}
+		if err := f.Broker.Nack(d, false); err != nil {
+			return err
+		}
+		return expectNothing(sub, f.Redelivery)
+	})
+}
+
+func checkPrefetch(f Fixture) error {
+	return withSubscription(f, 1, func(sub messaging.Subscription) error {
+		if err := publish(f, "first"); err != nil {
+			return err
+		}
+		if err := publish(f, "second"); err != nil {
+			return err
+		}
+		d, err := receive(sub, f.Redelivery)
+		if err != nil {
+			return err
+		}
+		if string(d.Body) != "first" {
+			return fmt.Errorf("got %q first, want in-order delivery", d.Body)
+		}
+		if err := expectNothing(sub, quietPeriod); err != nil {
+			return err
+		}
+		if err := f.Broker.Ack(d); err != nil {
+			return err
+		}
+		d, err = receive(sub, f.Redelivery)
+		if err != nil {
+			return err
+		}
+		if string(d.Body) != "second" {
+			return fmt.Errorf("got %q, want second", d.Body)
+		}
+		return f.Broker.Ack(d)
+	})
+}
+
+func checkCloseRequeue(f Fixture) error {
+	err := withSubscription(f, 1, func(sub messaging.Subscription) error {
+		if err := publish(f, "abandoned"); err != nil {
+			return err
+		}
+		_, err := receive(sub, f.Redelivery)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return withSubscription(f, 1, func(sub messaging.Subscription) error {
+		d, err := receive(sub, 2*f.Redelivery)
+		if err != nil {
+			return fmt.Errorf("abandoned message not redelivered: %w", err)
+		}
+		if string(d.Body) != "abandoned" || !d.Redelivered {
+			return fmt.Errorf("got %q redelivered=%v", d.Body, d.Redelivered)
+		}
+		return f.Broker.Ack(d)
+	})
+}
+
+// withSubscription runs fn on a fresh subscription and closes it afterwards
+// without settling anything fn left unacked
+func withSubscription(f Fixture, prefetch int, fn func(messaging.Subscription) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sub, err := f.Broker.Subscribe(ctx, f.Queue, messaging.SubscribeOptions{Prefetch: prefetch})
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+	return fn(sub)
+}
+
+func publish(f Fixture, body string) error {
+	return f.Broker.Publish(context.Background(), f.Exchange, f.RoutingKey, messaging.Message{Body: []byte(body), Mandatory: true})
+}
+
+func receive(sub messaging.Subscription, timeout time.Duration) (messaging.Delivery, error) {
+	select {
+	case d, ok := <-sub.Deliveries():
+		if !ok {
+			return messaging.Delivery{}, errors.New("subscription closed")
+		}
+		return d, nil
+	case <-time.After(timeout):
+		return messaging.Delivery{}, fmt.Errorf("nothing delivered within %s", timeout)
+	}
+}
+
+func expectNothing(sub messaging.Subscription, wait time.Duration) error {
+	select {
+	case d, ok := <-sub.Deliveries():
+		if ok {
+			return fmt.Errorf("unexpected delivery %q", d.Body)
+		}
+		return nil
+	case <-time.After(wait):
+		return nil
+	}
+}
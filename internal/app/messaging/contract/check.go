@@ -0,0 +1,219 @@
+package contract
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Fixtures are messages as they were produced by each schema version. They
+// are never edited once released: a new version adds a new file.
+//
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+type fixture struct {
+	Message  json.RawMessage `json:"message"`
+	Expected json.RawMessage `json:"expected"`
+}
+
+// Check verifies the contract against the released fixtures:
+//   - every fixture still decodes (through the upcasters) to its expected value
+//   - encoding a message today produces the same envelope and payload fields
+//     as the fixture for the current version
+//   - each registered type has a fixture for its current version
+//
+// It returns one error per broken expectation. TestContract runs it under
+// go test.
+func Check() []error {
+	var errs []error
+
+	entries, err := fixtures.ReadDir("fixtures")
+	if err != nil {
+		return []error{err}
+	}
+
+	latest := map[string]fixture{}
+	for _, entry := range entries {
+		name := entry.Name()
+		msgType, version, ok := parseFixtureName(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: fixture name must be <type>.v<version>[.note].json", name))
+			continue
+		}
+
+		raw, err := fixtures.ReadFile(path.Join("fixtures", name))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		var f fixture
+		if err := json.Unmarshal(raw, &f); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		if err := checkDecode(msgType, f); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		if current, err := CurrentVersion(msgType); err == nil && version == current && !strings.Contains(name, ".bare.") {
+			latest[msgType] = f
+		}
+	}
+
+	types := make([]string, 0, len(schemas))
+	for msgType := range schemas {
+		types = append(types, msgType)
+	}
+	sort.Strings(types)
+
+	for _, msgType := range types {
+		f, ok := latest[msgType]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: no fixture for current version v%d", msgType, schemas[msgType].current))
+			continue
+		}
+		if err := checkEncode(msgType, f); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", msgType, err))
+		}
+	}
+	return errs
+}
+
+// checkDecode decodes the fixture message as a consumer would
+func checkDecode(msgType string, f fixture) error {
+	switch msgType {
+	case TypeTopUpCreated:
+		got, _, err := DecodeTopUpCreated(f.Message)
+		if err != nil {
+			return err
+		}
+		var want TopUpCreated
+		if err := json.Unmarshal(f.Expected, &want); err != nil {
+			return err
+		}
+		if !got.Amount.Equal(want.Amount) || !got.CreatedAt.Equal(want.CreatedAt) {
+			return fmt.Errorf("decoded %+v, want %+v", *got, want)
+		}
+		got.Amount, got.CreatedAt = want.Amount, want.CreatedAt
+		if !reflect.DeepEqual(*got, want) {
+			return fmt.Errorf("decoded %+v, want %+v", *got, want)
+		}
+		return nil
+	default:
+		s, ok := schemas[msgType]
+		if !ok || s.payload == nil {
+			return fmt.Errorf("%w: %s has no decode check", ErrUnknownType, msgType)
+		}
+		got, want := s.payload(), s.payload()
+		if _, err := DecodeEvent(f.Message, msgType, got); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(f.Expected, want); err != nil {
+			return err
+		}
+		// Compare the encodings so decimals and times compare by value
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		if string(gotJSON) != string(wantJSON) {
+			return fmt.Errorf("decoded %s, want %s", gotJSON, wantJSON)
+		}
+		return nil
+	}
+}
+
+// checkEncode re-encodes the expected value as a producer would and compares
+// the field names with the current fixture
+func checkEncode(msgType string, f fixture) error {
+	var env *Envelope
+	var err error
+
+	switch msgType {
+	case TypeTopUpCreated:
+		var m TopUpCreated
+		if err := json.Unmarshal(f.Expected, &m); err != nil {
+			return err
+		}
+		env, err = NewTopUpCreated(&m)
+	default:
+		s, ok := schemas[msgType]
+		if !ok || s.payload == nil {
+			return fmt.Errorf("%w: %s has no encode check", ErrUnknownType, msgType)
+		}
+		m := s.payload()
+		if err := json.Unmarshal(f.Expected, m); err != nil {
+			return err
+		}
+		env, err = NewEnvelope(msgType, m, "check")
+	}
+	if err != nil {
+		return err
+	}
+
+	body, err := env.Marshal()
+	if err != nil {
+		return err
+	}
+
+	var want, got struct {
+		Envelope map[string]json.RawMessage
+		Payload  map[string]json.RawMessage
+	}
+	if err := splitEnvelope(f.Message, &want.Envelope, &want.Payload); err != nil {
+		return err
+	}
+	if err := splitEnvelope(body, &got.Envelope, &got.Payload); err != nil {
+		return err
+	}
+
+	if diff := keyDiff(want.Envelope, got.Envelope); diff != "" {
+		return fmt.Errorf("envelope fields changed: %s", diff)
+	}
+	if diff := keyDiff(want.Payload, got.Payload); diff != "" {
+		return fmt.Errorf("payload fields changed: %s", diff)
+	}
+	return nil
+}
+
+func splitEnvelope(body []byte, envelope, payload *map[string]json.RawMessage) error {
+	if err := json.Unmarshal(body, envelope); err != nil {
+		return err
+	}
+	return json.Unmarshal((*envelope)["payload"], payload)
+}
+
+func keyDiff(want, got map[string]json.RawMessage) string {
+	var missing, extra []string
+	for k := range want {
+		if _, ok := got[k]; !ok {
+			missing = append(missing, k)
+		}
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok {
+			extra = append(extra, k)
+		}
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		return ""
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return fmt.Sprintf("missing %v, unexpected %v", missing, extra)
+}
+
+// parseFixtureName splits "topup.created.v2.json" into its type and version
+func parseFixtureName(name string) (string, int, bool) {
+	parts := strings.Split(strings.TrimSuffix(name, ".json"), ".")
+	for i := len(parts) - 1; i > 0; i-- {
+		var version int
+		if _, err := fmt.Sscanf(parts[i], "v%d", &version); err == nil && parts[i] == fmt.Sprintf("v%d", version) {
+			return strings.Join(parts[:i], "."), version, true
+		}
+	}
+	return "", 0, false
+}
@@ -0,0 +1,11 @@
+package contract
+
+import "testing"
+
+// TestContract fails when a publisher or consumer change would break
+// messages already on the wire; see Check
+func TestContract(t *testing.T) {
+	for _, err := range Check() {
+		t.Error(err)
+	}
+}
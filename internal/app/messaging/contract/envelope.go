@@ -0,0 +1,140 @@
+// Package contract holds the message schemas shared by publishers and
+// consumers. Every message travels in an Envelope; payloads from older schema
+// versions are brought up to date by upcasters before they reach a handler.
+package contract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMalformed          = errors.New("contract: malformed message")
+	ErrUnknownType        = errors.New("contract: unknown message type")
+	ErrUnsupportedVersion = errors.New("contract: unsupported schema version")
+	ErrTypeMismatch       = errors.New("contract: unexpected message type")
+)
+
+// Envelope wraps every payload on the wire
+type Envelope struct {
+	Type          string          `json:"type"`
+	Version       int             `json:"version"`
+	MessageID     string          `json:"messageId"`
+	CorrelationID string          `json:"correlationId,omitempty"`
+	ProducedAt    time.Time       `json:"producedAt"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// Upcaster rewrites a payload of version n into version n+1
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+type schema struct {
+	current   int
+	upcasters map[int]Upcaster // keyed by the version they upgrade from
+	// legacy, when set, recognises bare payloads sent before envelopes existed
+	// and returns their version
+	legacy func(raw map[string]json.RawMessage) (int, bool)
+	// payload, when set, returns a pointer to the current payload struct so
+	// Check can decode and encode the type generically
+	payload func() interface{}
+}
+
+var schemas = map[string]schema{}
+
+func register(msgType string, s schema) {
+	schemas[msgType] = s
+}
+
+// CurrentVersion is the version producers write for msgType
+func CurrentVersion(msgType string) (int, error) {
+	s, ok := schemas[msgType]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownType, msgType)
+	}
+	return s.current, nil
+}
+
+// NewEnvelope wraps payload at the current version of msgType
+func NewEnvelope(msgType string, payload interface{}, correlationID string) (*Envelope, error) {
+	version, err := CurrentVersion(msgType)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		Type:          msgType,
+		Version:       version,
+		MessageID:     uuid.New().String(),
+		CorrelationID: correlationID,
+		ProducedAt:    time.Now().UTC(),
+		Payload:       body,
+	}, nil
+}
+
+func (e *Envelope) Marshal() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Decode parses a message and upcasts its payload to the current version of
+// its type. Bare legacy payloads are wrapped in an envelope first.
+func Decode(body []byte) (*Envelope, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	var env Envelope
+	if _, ok := raw["type"]; ok {
+		if err := json.Unmarshal(body, &env); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+	} else {
+		legacy, ok := detectLegacy(raw)
+		if !ok {
+			return nil, fmt.Errorf("%w: no envelope type", ErrMalformed)
+		}
+		env = legacy
+		env.Payload = body
+	}
+
+	s, ok := schemas[env.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownType, env.Type)
+	}
+	if env.Version < 1 || env.Version > s.current {
+		return nil, fmt.Errorf("%w: %s v%d (current v%d)", ErrUnsupportedVersion, env.Type, env.Version, s.current)
+	}
+
+	for env.Version < s.current {
+		up, ok := s.upcasters[env.Version]
+		if !ok {
+			return nil, fmt.Errorf("%w: no upcaster for %s v%d", ErrUnsupportedVersion, env.Type, env.Version)
+		}
+		payload, err := up(env.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("%w: upcasting %s v%d: %v", ErrMalformed, env.Type, env.Version, err)
+		}
+		env.Payload = payload
+		env.Version++
+	}
+	return &env, nil
+}
+
+func detectLegacy(raw map[string]json.RawMessage) (Envelope, bool) {
+	for msgType, s := range schemas {
+		if s.legacy == nil {
+			continue
+		}
+		if version, ok := s.legacy(raw); ok {
+			return Envelope{Type: msgType, Version: version}, true
+		}
+	}
+	return Envelope{}, false
+}
@@ -0,0 +1,93 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Domain events are published on the events topic exchange with the event
+// type as routing key, so consumers can bind to e.g. "user.*" or
+// "transaction.#". All are at version 1.
+const (
+	TypeUserRegistered       = "user.registered"
+	TypeUserDeleted          = "user.deleted"
+	TypeAccountOpened        = "account.opened"
+	TypeTransactionCompleted = "transaction.completed"
+	TypeTransactionFailed    = "transaction.failed"
+)
+
+// UserRegistered is raised when a user signs up or is created by an admin
+type UserRegistered struct {
+	UserID       uint64    `json:"userId"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	RegisteredAt time.Time `json:"registeredAt"`
+}
+
+// UserDeleted is raised after a user is removed
+type UserDeleted struct {
+	UserID    uint64    `json:"userId"`
+	Email     string    `json:"email"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// AccountOpened is raised when an account is provisioned for a user
+type AccountOpened struct {
+	AccountNumber string    `json:"accountNumber"`
+	UserID        uint64    `json:"userId"`
+	Currency      string    `json:"currency"`
+	OpenedAt      time.Time `json:"openedAt"`
+}
+
+// TransactionCompleted is raised once a transaction has moved the money
+type TransactionCompleted struct {
+	TransactionID string          `json:"transactionId"`
+	Type          string          `json:"type"`
+	AccountNumber string          `json:"accountNumber"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+	Reference     string          `json:"reference,omitempty"`
+	CompletedAt   time.Time       `json:"completedAt"`
+}
+
+// TransactionFailed is raised when a transaction ends without moving money.
+// Reason is the final transaction status, e.g. failed_account_blocked.
+type TransactionFailed struct {
+	TransactionID string          `json:"transactionId"`
+	Type          string          `json:"type"`
+	AccountNumber string          `json:"accountNumber"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+	Reason        string          `json:"reason"`
+	FailedAt      time.Time       `json:"failedAt"`
+}
+
+func init() {
+	registerEvent(TypeUserRegistered, func() interface{} { return &UserRegistered{} })
+	registerEvent(TypeUserDeleted, func() interface{} { return &UserDeleted{} })
+	registerEvent(TypeAccountOpened, func() interface{} { return &AccountOpened{} })
+	registerEvent(TypeTransactionCompleted, func() interface{} { return &TransactionCompleted{} })
+	registerEvent(TypeTransactionFailed, func() interface{} { return &TransactionFailed{} })
+}
+
+func registerEvent(msgType string, payload func() interface{}) {
+	register(msgType, schema{current: 1, payload: payload})
+}
+
+// DecodeEvent decodes a message of msgType into v, e.g. a *UserRegistered
+func DecodeEvent(body []byte, msgType string, v interface{}) (*Envelope, error) {
+	env, err := Decode(body)
+	if err != nil {
+		return nil, err
+	}
+	if env.Type != msgType {
+		return env, fmt.Errorf("%w: %s", ErrTypeMismatch, env.Type)
+	}
+	if err := json.Unmarshal(env.Payload, v); err != nil {
+		return env, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	return env, nil
+}
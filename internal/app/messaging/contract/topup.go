@@ -0,0 +1,79 @@
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TypeTopUpCreated is published when a top-up is accepted and waits for the
+// worker to credit the account
+const TypeTopUpCreated = "topup.created"
+
+// TopUpCreated is version 2 of the top-up payload.
+//
+//	v1: transactionId, accountNumber, amount, currency (optional), createdAt.
+//	    Sent as a bare JSON object before envelopes were introduced.
+//	v2: currency is required and reference carries the caller's reference
+//	    (e.g. "va:<bank reference>" for virtual account credits).
+type TopUpCreated struct {
+	TransactionID string          `json:"transactionId"`
+	AccountNumber string          `json:"accountNumber"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+	Reference     string          `json:"reference,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+func init() {
+	register(TypeTopUpCreated, schema{
+		current: 2,
+		upcasters: map[int]Upcaster{
+			1: upcastTopUpCreatedV1,
+		},
+		legacy: func(raw map[string]json.RawMessage) (int, bool) {
+			_, hasTx := raw["transactionId"]
+			_, hasAccount := raw["accountNumber"]
+			return 1, hasTx && hasAccount
+		},
+	})
+}
+
+// upcastTopUpCreatedV1 fills the currency every v1 producer assumed
+func upcastTopUpCreatedV1(payload json.RawMessage) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, err
+	}
+	if currency, ok := fields["currency"]; !ok || string(currency) == `""` || string(currency) == "null" {
+		fields["currency"] = json.RawMessage(`"IDR"`)
+	}
+	return json.Marshal(fields)
+}
+
+// NewTopUpCreated wraps a top-up in an envelope correlated by its transaction id
+func NewTopUpCreated(m *TopUpCreated) (*Envelope, error) {
+	return NewEnvelope(TypeTopUpCreated, m, m.TransactionID)
+}
+
+// DecodeTopUpCreated decodes any supported version of a top-up message
+func DecodeTopUpCreated(body []byte) (*TopUpCreated, *Envelope, error) {
+	env, err := Decode(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if env.Type != TypeTopUpCreated {
+		return nil, env, fmt.Errorf("%w: %s", ErrTypeMismatch, env.Type)
+	}
+
+	var m TopUpCreated
+	if err := json.Unmarshal(env.Payload, &m); err != nil {
+		return nil, env, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+	if m.TransactionID == "" || m.AccountNumber == "" {
+		return nil, env, fmt.Errorf("%w: transactionId and accountNumber are required", ErrMalformed)
+	}
+	return &m, env, nil
+}
@@ -0,0 +1,26 @@
+package messaging
+
+import "time"
+
+// DeadLetter is a message parked in a dead-letter queue
+type DeadLetter struct {
+	MessageID  string
+	Exchange   string // exchange the message was originally published to
+	RoutingKey string
+	Body       []byte
+	Headers    map[string]interface{}
+	Reason     string // first dead-letter reason (rejected, expired, ...)
+	Attempts   int
+	Timestamp  time.Time
+}
+
+// DeadLetterPort gives operators access to a dead-letter queue. Messages that
+// are not selected stay in the queue in their original order.
+type DeadLetterPort interface {
+	Peek(queue string, limit int) ([]DeadLetter, error)
+	// Replay republishes the selected messages to their original exchange.
+	// A body in edits replaces the message body before replaying.
+	Replay(queue string, ids []string, edits map[string][]byte) ([]string, error)
+	// Purge removes the selected messages, or everything when ids is empty
+	Purge(queue string, ids []string) (int, error)
+}
@@ -0,0 +1,22 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotScheduled is returned by CancelDelayed when no pending message has
+// the ID, because it was never scheduled, already went out or was cancelled
+var ErrNotScheduled = errors.New("messaging: no pending delayed message with this id")
+
+// DelayedPublisher publishes a message once a delay has passed, for flows such
+// as "expire this top-up in 15 minutes"
+type DelayedPublisher interface {
+	// PublishDelayed schedules msg for exchange and routingKey and returns the
+	// ID to cancel it with: msg.ID, or a generated one when that is empty
+	PublishDelayed(ctx context.Context, exchange, routingKey string, msg Message, delay time.Duration) (string, error)
+	// CancelDelayed stops a pending message. Once the message is due it may
+	// already be on its way, so consumers should still tolerate a late copy.
+	CancelDelayed(ctx context.Context, messageID string) error
+}
@@ -0,0 +1,63 @@
+package messaging
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
+)
+
+// EventsExchange is the topic exchange carrying domain events. The routing
+// key is the event type, e.g. user.registered.
+const EventsExchange = "domain.events"
+
+const eventPublishTimeout = 5 * time.Second
+
+// EventPublisher raises domain events for other services. Events are sent
+// after the state change is committed and on a best-effort basis: failures
+// are logged, never returned to the caller.
+type EventPublisher interface {
+	Publish(msgType string, payload interface{}, correlationID string)
+}
+
+type brokerEventPublisher struct {
+	broker BrokerPort
+	logger *log.Logger
+}
+
+// NewEventPublisher publishes domain events on EventsExchange. A nil broker
+// drops every event.
+func NewEventPublisher(broker BrokerPort, logger *log.Logger) EventPublisher {
+	return &brokerEventPublisher{broker: broker, logger: logger}
+}
+
+func (p *brokerEventPublisher) Publish(msgType string, payload interface{}, correlationID string) {
+	if p.broker == nil {
+		return
+	}
+
+	env, err := contract.NewEnvelope(msgType, payload, correlationID)
+	if err != nil {
+		p.logger.Printf("events: build %s: %v", msgType, err)
+		return
+	}
+	body, err := env.Marshal()
+	if err != nil {
+		p.logger.Printf("events: marshal %s: %v", msgType, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventPublishTimeout)
+	defer cancel()
+
+	// Not mandatory: an event nobody subscribes to yet is not an error
+	if err := p.broker.Publish(ctx, EventsExchange, msgType, Message{
+		ID:          env.MessageID,
+		ContentType: "application/json",
+		Timestamp:   env.ProducedAt,
+		Body:        body,
+	}); err != nil {
+		p.logger.Printf("events: publish %s %s: %v", msgType, env.MessageID, err)
+	}
+}
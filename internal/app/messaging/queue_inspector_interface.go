@@ -0,0 +1,17 @@
+package messaging
+
+import "context"
+
+// QueueStats is a snapshot of a queue as the broker reports it
+type QueueStats struct {
+	// Messages waiting to be delivered
+	Messages int
+	// Consumers currently attached to the queue
+	Consumers int
+}
+
+// QueueInspector reads queue depth without consuming, so callers can shed
+// load before a backlog grows
+type QueueInspector interface {
+	QueueStats(ctx context.Context, queue string) (QueueStats, error)
+}
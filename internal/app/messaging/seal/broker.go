@@ -0,0 +1,123 @@
+package seal
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+var errNoInspector = errors.New("seal: broker cannot report queue stats")
+
+// Broker seals what is published to the chosen exchanges and opens every
+// sealed delivery before handing it on. A delivery that fails to open, or one
+// on a required queue that is unsigned or, when the sealer encrypts,
+// unencrypted, is dead-lettered and never reaches the subscriber.
+type Broker struct {
+	messaging.BrokerPort
+	sealer    *Sealer
+	exchanges map[string]bool
+	required  map[string]bool
+	logger    *log.Logger
+}
+
+// NewBroker seals publishes to exchanges and requires sealed messages on the
+// queues in required
+func NewBroker(inner messaging.BrokerPort, sealer *Sealer, exchanges, required []string, logger *log.Logger) *Broker {
+	b := &Broker{
+		BrokerPort: inner,
+		sealer:     sealer,
+		exchanges:  map[string]bool{},
+		required:   map[string]bool{},
+		logger:     logger,
+	}
+	for _, e := range exchanges {
+		b.exchanges[e] = true
+	}
+	for _, q := range required {
+		b.required[q] = true
+	}
+	return b
+}
+
+func (b *Broker) Publish(ctx context.Context, exchange, routingKey string, msg messaging.Message) error {
+	if b.exchanges[exchange] {
+		body, err := b.sealer.Seal(msg.Body)
+		if err != nil {
+			return err
+		}
+		msg.Body = body
+		msg.ContentType = ContentType
+	}
+	return b.BrokerPort.Publish(ctx, exchange, routingKey, msg)
+}
+
+func (b *Broker) Subscribe(ctx context.Context, queue string, opts messaging.SubscribeOptions) (messaging.Subscription, error) {
+	inner, err := b.BrokerPort.Subscribe(ctx, queue, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscription{inner: inner, out: make(chan messaging.Delivery)}
+	go func() {
+		defer close(sub.out)
+		for d := range inner.Deliveries() {
+			if !b.open(queue, &d) {
+				continue
+			}
+			select {
+			case sub.out <- d:
+			case <-ctx.Done():
+				// Not handed out; closing the subscription returns it
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// QueueStats passes through to the wrapped broker so backpressure keeps working
+func (b *Broker) QueueStats(ctx context.Context, queue string) (messaging.QueueStats, error) {
+	inspector, ok := b.BrokerPort.(messaging.QueueInspector)
+	if !ok {
+		return messaging.QueueStats{}, errNoInspector
+	}
+	return inspector.QueueStats(ctx, queue)
+}
+
+// open replaces a sealed body with its payload. It dead-letters the delivery
+// and returns false when the body cannot be trusted.
+func (b *Broker) open(queue string, d *messaging.Delivery) bool {
+	body, info, err := b.sealer.Open(d.Body)
+	if err == nil && b.required[queue] {
+		switch {
+		case !info.Signed:
+			err = ErrUnsigned
+		case b.sealer.Encrypts() && !info.Encrypted:
+			err = ErrUnencrypted
+		}
+	}
+	if err != nil {
+		b.logger.Printf("%s: rejecting message %s: %v", queue, d.ID, err)
+		if nackErr := b.BrokerPort.Nack(*d, false); nackErr != nil {
+			b.logger.Printf("%s: reject %s: %v", queue, d.ID, nackErr)
+		}
+		return false
+	}
+	d.Body = body
+	return true
+}
+
+type subscription struct {
+	inner messaging.Subscription
+	out   chan messaging.Delivery
+}
+
+func (s *subscription) Deliveries() <-chan messaging.Delivery {
+	return s.out
+}
+
+func (s *subscription) Close() error {
+	return s.inner.Close()
+}
@@ -0,0 +1,166 @@
+package seal
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/service/membroker"
+)
+
+// logBuffer is a log sink the broker's delivery goroutine can write to safely
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (l *logBuffer) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.Write(p)
+}
+
+func (l *logBuffer) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+type brokerFixture struct {
+	mem    *membroker.Broker
+	sealed *Broker
+	logs   *logBuffer
+}
+
+func newBrokerFixture(t *testing.T, sealer *Sealer) *brokerFixture {
+	t.Helper()
+	mem := membroker.New()
+	t.Cleanup(func() { _ = mem.Close() })
+	mem.Bind("topup.exchange", "topup", "topup_queue")
+	mem.Bind("events.exchange", "user.created", "events_queue")
+
+	logs := &logBuffer{}
+	return &brokerFixture{
+		mem:    mem,
+		sealed: NewBroker(mem, sealer, []string{"topup.exchange"}, []string{"topup_queue"}, log.New(logs, "", 0)),
+		logs:   logs,
+	}
+}
+
+// receive subscribes through the sealed broker and returns the first delivery,
+// or false if none arrives in time
+func (f *brokerFixture) receive(t *testing.T, queue string) (messaging.Delivery, bool) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	sub, err := f.sealed.Subscribe(ctx, queue, messaging.SubscribeOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	select {
+	case d, ok := <-sub.Deliveries():
+		if ok {
+			if err := f.sealed.Ack(d); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return d, ok
+	case <-ctx.Done():
+		return messaging.Delivery{}, false
+	}
+}
+
+func TestBrokerSealsAndOpens(t *testing.T) {
+	sealer := newSealer(t, Options{
+		EncryptionKeys: map[string][]byte{"k1": aesKey(t)},
+		ActiveKeyID:    "k1",
+		SigningKeyID:   "s1",
+		SigningKey:     signingKey(t),
+	})
+	f := newBrokerFixture(t, sealer)
+	payload := []byte(`{"transactionId":"t-1"}`)
+
+	if err := f.sealed.Publish(context.Background(), "topup.exchange", "topup", messaging.Message{ID: "m-1", Body: payload}); err != nil {
+		t.Fatal(err)
+	}
+	queued := f.mem.Messages("topup_queue")
+	if len(queued) != 1 || queued[0].ContentType != ContentType || bytes.Contains(queued[0].Body, []byte("t-1")) {
+		t.Fatalf("queued message is not sealed: %+v", queued)
+	}
+
+	d, ok := f.receive(t, "topup_queue")
+	if !ok {
+		t.Fatalf("sealed message not delivered; log: %s", f.logs)
+	}
+	if !bytes.Equal(d.Body, payload) {
+		t.Errorf("body = %s, want %s", d.Body, payload)
+	}
+}
+
+func TestBrokerPassesUnsealedExchanges(t *testing.T) {
+	sealer := newSealer(t, Options{SigningKeyID: "s1", SigningKey: signingKey(t)})
+	f := newBrokerFixture(t, sealer)
+
+	if err := f.sealed.Publish(context.Background(), "events.exchange", "user.created", messaging.Message{Body: []byte("plain")}); err != nil {
+		t.Fatal(err)
+	}
+	d, ok := f.receive(t, "events_queue")
+	if !ok || string(d.Body) != "plain" {
+		t.Fatalf("plain event on an unrequired queue: got %q, %v", d.Body, ok)
+	}
+}
+
+func TestBrokerRejectsOnRequiredQueue(t *testing.T) {
+	enc := map[string][]byte{"k1": aesKey(t)}
+	sig := signingKey(t)
+	consumer := newSealer(t, Options{EncryptionKeys: enc, ActiveKeyID: "k1", SigningKeyID: "s1", SigningKey: sig})
+
+	mustSeal := func(s *Sealer) []byte {
+		body, err := s.Seal([]byte(`{"transactionId":"t-1"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return body
+	}
+	signedOnly := mustSeal(newSealer(t, Options{SigningKeyID: "s1", SigningKey: sig}))
+	encryptedOnly := mustSeal(newSealer(t, Options{EncryptionKeys: enc, ActiveKeyID: "k1"}))
+	tampered := rewrite(t, mustSeal(consumer), func(m *sealed) { m.Data[0] ^= 0x01 })
+
+	tests := []struct {
+		name string
+		body []byte
+		want error
+	}{
+		{"plain body", []byte(`{"transactionId":"t-1"}`), ErrUnsigned},
+		{"encrypted but unsigned", encryptedOnly, ErrUnsigned},
+		{"signed but unencrypted", signedOnly, ErrUnencrypted},
+		{"tampered", tampered, ErrTampered},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBrokerFixture(t, consumer)
+			// Publish straight to the inner broker, as a foreign producer would
+			if err := f.mem.Publish(context.Background(), "topup.exchange", "topup", messaging.Message{ID: "m-1", Body: tt.body}); err != nil {
+				t.Fatal(err)
+			}
+
+			if d, ok := f.receive(t, "topup_queue"); ok {
+				t.Fatalf("untrusted message reached the subscriber: %s", d.Body)
+			}
+			if dead := f.mem.DeadLetters("topup_queue"); len(dead) != 1 || dead[0].ID != "m-1" {
+				t.Fatalf("dead letters = %+v, want m-1", dead)
+			}
+			if !strings.Contains(f.logs.String(), tt.want.Error()) {
+				t.Errorf("log %q does not mention %v", f.logs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+// Package seal protects message bodies on a shared broker. A sealed body is
+// a small JSON wrapper holding the payload, encrypted with AES-GCM under a
+// key ID so keys can be rotated, and optionally signed with Ed25519 so
+// consumers can tell who produced it and that nobody changed it since.
+package seal
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ContentType marks a sealed message body
+const ContentType = "application/vnd.sealed+json"
+
+const (
+	formatVersion = 1
+	// domain separates seal signatures and AAD from any other use of the keys
+	domain = "seal.v1"
+)
+
+var (
+	ErrTampered    = errors.New("seal: message was modified or signed with another key")
+	ErrUnsigned    = errors.New("seal: message is not signed")
+	ErrUnencrypted = errors.New("seal: message is not encrypted")
+	ErrUnknownKey  = errors.New("seal: unknown key id")
+	ErrUnsupported = errors.New("seal: unsupported sealed format")
+	ErrInvalidKey  = errors.New("seal: invalid key")
+)
+
+// sealed is the wire format. Byte fields travel as base64.
+type sealed struct {
+	Seal int `json:"seal"`
+	// KeyID names the encryption key; empty when Data is the plain payload
+	KeyID       string `json:"kid,omitempty"`
+	Nonce       []byte `json:"nonce,omitempty"`
+	Data        []byte `json:"data"`
+	SignerKeyID string `json:"sigKid,omitempty"`
+	Signature   []byte `json:"sig,omitempty"`
+}
+
+// Info describes how an opened body was protected
+type Info struct {
+	Sealed      bool
+	Encrypted   bool
+	KeyID       string
+	Signed      bool
+	SignerKeyID string
+}
+
+// Options configures a Sealer. Either part may be left out: without
+// EncryptionKeys bodies are only signed, without SigningKey only encrypted.
+type Options struct {
+	// EncryptionKeys are AES keys (16, 24 or 32 bytes) by key ID. Every key can
+	// open; only ActiveKeyID seals.
+	EncryptionKeys map[string][]byte
+	ActiveKeyID    string
+	// SigningKey signs sealed bodies under SigningKeyID
+	SigningKeyID string
+	SigningKey   ed25519.PrivateKey
+	// VerifyKeys are the public keys of other producers and of retired
+	// signing keys; the signing key's own public key is always accepted
+	VerifyKeys map[string]ed25519.PublicKey
+}
+
+type Sealer struct {
+	ciphers    map[string]cipher.AEAD
+	active     string
+	signerID   string
+	signer     ed25519.PrivateKey
+	verifyKeys map[string]ed25519.PublicKey
+}
+
+func New(opts Options) (*Sealer, error) {
+	s := &Sealer{
+		ciphers:    map[string]cipher.AEAD{},
+		signerID:   opts.SigningKeyID,
+		signer:     opts.SigningKey,
+		verifyKeys: map[string]ed25519.PublicKey{},
+	}
+
+	for id, key := range opts.EncryptionKeys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("%w: encryption key %q: %v", ErrInvalidKey, id, err)
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		s.ciphers[id] = gcm
+	}
+	if len(s.ciphers) > 0 {
+		if _, ok := s.ciphers[opts.ActiveKeyID]; !ok {
+			return nil, fmt.Errorf("%w: active encryption key %q is not configured", ErrInvalidKey, opts.ActiveKeyID)
+		}
+		s.active = opts.ActiveKeyID
+	}
+
+	for id, pub := range opts.VerifyKeys {
+		if len(pub) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("%w: verify key %q must be %d bytes", ErrInvalidKey, id, ed25519.PublicKeySize)
+		}
+		s.verifyKeys[id] = pub
+	}
+	if opts.SigningKey != nil {
+		if len(opts.SigningKey) != ed25519.PrivateKeySize || opts.SigningKeyID == "" {
+			return nil, fmt.Errorf("%w: signing key needs an id and %d bytes", ErrInvalidKey, ed25519.PrivateKeySize)
+		}
+		s.verifyKeys[opts.SigningKeyID] = opts.SigningKey.Public().(ed25519.PublicKey)
+	}
+	return s, nil
+}
+
+// Encrypts reports whether Seal encrypts; Signs whether it signs
+func (s *Sealer) Encrypts() bool { return s.active != "" }
+func (s *Sealer) Signs() bool    { return s.signer != nil }
+
+// Seal wraps body, encrypting and signing it as configured
+func (s *Sealer) Seal(body []byte) ([]byte, error) {
+	out := sealed{Seal: formatVersion, Data: body}
+
+	if s.active != "" {
+		gcm := s.ciphers[s.active]
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		out.KeyID = s.active
+		out.Nonce = nonce
+		out.Data = gcm.Seal(nil, nonce, body, aad(s.active))
+	}
+	if s.signer != nil {
+		out.SignerKeyID = s.signerID
+		out.Signature = ed25519.Sign(s.signer, signingInput(&out))
+	}
+	return json.Marshal(out)
+}
+
+// Open returns the payload of a sealed body after checking its signature and
+// decrypting it. Bodies that are not sealed come back unchanged with a zero
+// Info; callers that require protection check Info.
+func (s *Sealer) Open(body []byte) ([]byte, Info, error) {
+	in, ok := parse(body)
+	if !ok {
+		return body, Info{}, nil
+	}
+	info := Info{Sealed: true}
+	if in.Seal != formatVersion {
+		return nil, info, fmt.Errorf("%w: version %d", ErrUnsupported, in.Seal)
+	}
+
+	if len(in.Signature) > 0 {
+		pub, ok := s.verifyKeys[in.SignerKeyID]
+		if !ok {
+			return nil, info, fmt.Errorf("%w: signer %q", ErrUnknownKey, in.SignerKeyID)
+		}
+		if !ed25519.Verify(pub, signingInput(in), in.Signature) {
+			return nil, info, ErrTampered
+		}
+		info.Signed = true
+		info.SignerKeyID = in.SignerKeyID
+	}
+
+	if in.KeyID == "" {
+		return in.Data, info, nil
+	}
+	gcm, ok := s.ciphers[in.KeyID]
+	if !ok {
+		return nil, info, fmt.Errorf("%w: encryption key %q", ErrUnknownKey, in.KeyID)
+	}
+	if len(in.Nonce) != gcm.NonceSize() {
+		return nil, info, ErrTampered
+	}
+	plain, err := gcm.Open(nil, in.Nonce, in.Data, aad(in.KeyID))
+	if err != nil {
+		return nil, info, ErrTampered
+	}
+	info.Encrypted = true
+	info.KeyID = in.KeyID
+	return plain, info, nil
+}
+
+// IsSealed reports whether body is a sealed wrapper, without opening it
+func IsSealed(body []byte) bool {
+	_, ok := parse(body)
+	return ok
+}
+
+func parse(body []byte) (*sealed, bool) {
+	var in sealed
+	if err := json.Unmarshal(body, &in); err != nil || in.Seal == 0 || in.Data == nil {
+		return nil, false
+	}
+	return &in, true
+}
+
+func aad(keyID string) []byte {
+	return []byte(domain + "\x00" + keyID)
+}
+
+// signingInput covers everything but the signature, each field length
+// prefixed so fields cannot be shifted into one another
+func signingInput(m *sealed) []byte {
+	buf := []byte(domain)
+	for _, field := range [][]byte{[]byte(m.KeyID), m.Nonce, m.Data, []byte(m.SignerKeyID)} {
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
+	return buf
+}
+
+// ParseKeys reads "id:base64,id:base64" as used by the MESSAGE_*_KEYS
+// settings. Standard and URL-safe base64 are both accepted.
+func ParseKeys(spec string) (map[string][]byte, []string, error) {
+	keys := map[string][]byte{}
+	var order []string
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(item, ":")
+		if !ok || id == "" {
+			return nil, nil, fmt.Errorf("%w: %q is not id:base64", ErrInvalidKey, item)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			if key, err = base64.URLEncoding.DecodeString(encoded); err != nil {
+				return nil, nil, fmt.Errorf("%w: key %q is not base64", ErrInvalidKey, id)
+			}
+		}
+		if _, dup := keys[id]; dup {
+			return nil, nil, fmt.Errorf("%w: key %q listed twice", ErrInvalidKey, id)
+		}
+		keys[id] = key
+		order = append(order, id)
+	}
+	return keys, order, nil
+}
@@ -0,0 +1,264 @@
+package seal
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func aesKey(t *testing.T) []byte {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signingKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return priv
+}
+
+func newSealer(t *testing.T, opts Options) *Sealer {
+	t.Helper()
+	s, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// rewrite decodes a sealed body, lets edit change it and encodes it again
+func rewrite(t *testing.T, body []byte, edit func(*sealed)) []byte {
+	t.Helper()
+	in, ok := parse(body)
+	if !ok {
+		t.Fatalf("not a sealed body: %s", body)
+	}
+	edit(in)
+	out, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestSealOpen(t *testing.T) {
+	enc := map[string][]byte{"k1": aesKey(t)}
+	sig := signingKey(t)
+	payload := []byte(`{"transactionId":"t-1","amount":"15000"}`)
+
+	tests := []struct {
+		name string
+		opts Options
+		want Info
+	}{
+		{
+			name: "encrypted and signed",
+			opts: Options{EncryptionKeys: enc, ActiveKeyID: "k1", SigningKeyID: "s1", SigningKey: sig},
+			want: Info{Sealed: true, Encrypted: true, KeyID: "k1", Signed: true, SignerKeyID: "s1"},
+		},
+		{
+			name: "encrypted only",
+			opts: Options{EncryptionKeys: enc, ActiveKeyID: "k1"},
+			want: Info{Sealed: true, Encrypted: true, KeyID: "k1"},
+		},
+		{
+			name: "signed only",
+			opts: Options{SigningKeyID: "s1", SigningKey: sig},
+			want: Info{Sealed: true, Signed: true, SignerKeyID: "s1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSealer(t, tt.opts)
+			body, err := s.Seal(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsSealed(body) {
+				t.Fatal("sealed body not recognised")
+			}
+			if s.Encrypts() && bytes.Contains(body, []byte("t-1")) {
+				t.Fatal("payload visible in encrypted body")
+			}
+
+			got, info, err := s.Open(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, payload) {
+				t.Errorf("payload = %s, want %s", got, payload)
+			}
+			if info != tt.want {
+				t.Errorf("info = %+v, want %+v", info, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenPlainBody(t *testing.T) {
+	s := newSealer(t, Options{EncryptionKeys: map[string][]byte{"k1": aesKey(t)}, ActiveKeyID: "k1"})
+	plain := []byte(`{"transactionId":"t-1"}`)
+
+	got, info, err := s.Open(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plain) || info != (Info{}) {
+		t.Errorf("plain body came back as %s with %+v", got, info)
+	}
+}
+
+func TestOpenTampered(t *testing.T) {
+	enc := map[string][]byte{"k1": aesKey(t)}
+	sig := signingKey(t)
+	signed := newSealer(t, Options{EncryptionKeys: enc, ActiveKeyID: "k1", SigningKeyID: "s1", SigningKey: sig})
+	unsigned := newSealer(t, Options{EncryptionKeys: enc, ActiveKeyID: "k1"})
+
+	tests := []struct {
+		name   string
+		sealer *Sealer
+		edit   func(*sealed)
+	}{
+		{"flipped ciphertext byte", signed, func(m *sealed) { m.Data[0] ^= 0x01 }},
+		{"flipped signature byte", signed, func(m *sealed) { m.Signature[0] ^= 0x01 }},
+		{"flipped nonce byte", signed, func(m *sealed) { m.Nonce[0] ^= 0x01 }},
+		{"ciphertext moved to another key id", signed, func(m *sealed) { m.KeyID = "k2" }},
+		{"flipped ciphertext byte without signature", unsigned, func(m *sealed) { m.Data[len(m.Data)-1] ^= 0x80 }},
+		{"short nonce", unsigned, func(m *sealed) { m.Nonce = m.Nonce[:4] }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := tt.sealer.Seal([]byte("payload"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _, err = tt.sealer.Open(rewrite(t, body, tt.edit))
+			if !errors.Is(err, ErrTampered) {
+				t.Fatalf("err = %v, want ErrTampered", err)
+			}
+		})
+	}
+}
+
+func TestOpenUnknownKey(t *testing.T) {
+	producer := newSealer(t, Options{
+		EncryptionKeys: map[string][]byte{"k1": aesKey(t)},
+		ActiveKeyID:    "k1",
+		SigningKeyID:   "s1",
+		SigningKey:     signingKey(t),
+	})
+	body, err := producer.Seal([]byte("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		consumer *Sealer
+	}{
+		{"unknown signer", newSealer(t, Options{EncryptionKeys: map[string][]byte{"k1": aesKey(t)}, ActiveKeyID: "k1"})},
+		{"unknown encryption key", newSealer(t, Options{
+			EncryptionKeys: map[string][]byte{"k2": aesKey(t)},
+			ActiveKeyID:    "k2",
+			VerifyKeys:     map[string]ed25519.PublicKey{"s1": producer.signer.Public().(ed25519.PublicKey)},
+		})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.consumer.Open(body); !errors.Is(err, ErrUnknownKey) {
+				t.Fatalf("err = %v, want ErrUnknownKey", err)
+			}
+		})
+	}
+}
+
+func TestOpenAfterRotation(t *testing.T) {
+	k1, k2 := aesKey(t), aesKey(t)
+	s1, s2 := signingKey(t), signingKey(t)
+
+	before := newSealer(t, Options{
+		EncryptionKeys: map[string][]byte{"k1": k1},
+		ActiveKeyID:    "k1",
+		SigningKeyID:   "s1",
+		SigningKey:     s1,
+	})
+	// k2 and s2 take over; k1 and s1 stay around to open what is in flight
+	after := newSealer(t, Options{
+		EncryptionKeys: map[string][]byte{"k1": k1, "k2": k2},
+		ActiveKeyID:    "k2",
+		SigningKeyID:   "s2",
+		SigningKey:     s2,
+		VerifyKeys:     map[string]ed25519.PublicKey{"s1": s1.Public().(ed25519.PublicKey)},
+	})
+
+	old, err := before.Seal([]byte("in flight"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, info, err := after.Open(old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "in flight" || info.KeyID != "k1" || info.SignerKeyID != "s1" {
+		t.Errorf("opened %q with %+v", got, info)
+	}
+
+	fresh, err := after.Seal([]byte("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, info, err := after.Open(fresh); err != nil || info.KeyID != "k2" || info.SignerKeyID != "s2" {
+		t.Errorf("fresh message: %+v, %v", info, err)
+	}
+	if _, _, err := before.Open(fresh); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("old sealer opening new key: err = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestNewRejectsBadKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"short aes key", Options{EncryptionKeys: map[string][]byte{"k1": make([]byte, 10)}, ActiveKeyID: "k1"}},
+		{"active key missing", Options{EncryptionKeys: map[string][]byte{"k1": make([]byte, 32)}, ActiveKeyID: "k2"}},
+		{"signing key without id", Options{SigningKey: signingKey(t)}},
+		{"short verify key", Options{VerifyKeys: map[string]ed25519.PublicKey{"s1": make([]byte, 8)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.opts); !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("err = %v, want ErrInvalidKey", err)
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	keys, order, err := ParseKeys(" k1:AAECAw== , k2:_-8= ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(order) != 2 || order[0] != "k1" || order[1] != "k2" {
+		t.Fatalf("order = %v", order)
+	}
+	if !bytes.Equal(keys["k1"], []byte{0, 1, 2, 3}) || !bytes.Equal(keys["k2"], []byte{0xff, 0xef}) {
+		t.Errorf("keys = %v", keys)
+	}
+
+	for _, spec := range []string{"k1", ":AAAA", "k1:not base64!", "k1:AAAA,k1:AAAA"} {
+		if _, _, err := ParseKeys(spec); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKeys(%q) err = %v, want ErrInvalidKey", spec, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+const (
+	defaultBackpressureRetryAfter = 30 * time.Second
+	defaultBackpressureCacheTTL   = time.Second
+)
+
+var (
+	ErrQueueBacklogged = errors.New("Top-up queue is backlogged, retry later")
+	ErrNoConsumers     = errors.New("No worker is processing top-ups, retry later")
+)
+
+// ThrottledError rejects a top-up because of the queue's state. It wraps
+// ErrQueueBacklogged or ErrNoConsumers and tells the client when to retry.
+type ThrottledError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string { return e.Err.Error() }
+func (e *ThrottledError) Unwrap() error { return e.Err }
+
+// BackpressureConfig sets when top-ups are refused. Zero values disable the
+// matching check.
+type BackpressureConfig struct {
+	// MaxDepth is how many messages may wait in a lane's queue before new
+	// top-ups get ErrQueueBacklogged
+	MaxDepth int
+	// MinConsumers is how many consumers a lane needs before top-ups are
+	// accepted; below it they get ErrNoConsumers
+	MinConsumers int
+	// RetryAfter is what clients are told to wait (default 30s)
+	RetryAfter time.Duration
+	// CacheTTL is how long a queue reading is reused so a burst of requests
+	// does not hammer the broker (default 1s)
+	CacheTTL time.Duration
+}
+
+// Backpressure checks the queue of a lane before a top-up is accepted. When
+// the broker cannot be inspected the top-up goes through; the publish then
+// reports whether the broker is really down.
+type Backpressure struct {
+	inspector messaging.QueueInspector
+	queues    map[string]string // lane -> queue
+	cfg       BackpressureConfig
+
+	mu       sync.Mutex
+	readings map[string]reading
+}
+
+type reading struct {
+	stats messaging.QueueStats
+	at    time.Time
+}
+
+var (
+	backpressureOnce sync.Once
+	backpressureVars *expvar.Map
+)
+
+// NewBackpressure guards the given lanes, mapped to their queue names.
+// Readings are published under the "backpressure" expvar at /debug/vars:
+// "<queue>.messages" and "<queue>.consumers" hold the last reading and
+// "<queue>.throttled" counts refused top-ups.
+func NewBackpressure(inspector messaging.QueueInspector, queues map[string]string, cfg BackpressureConfig) *Backpressure {
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = defaultBackpressureRetryAfter
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultBackpressureCacheTTL
+	}
+	backpressureOnce.Do(func() {
+		backpressureVars = expvar.NewMap("backpressure")
+	})
+	return &Backpressure{
+		inspector: inspector,
+		queues:    queues,
+		cfg:       cfg,
+		readings:  map[string]reading{},
+	}
+}
+
+// Check returns a *ThrottledError when the lane's queue is over its limits
+func (b *Backpressure) Check(ctx context.Context, lane string) error {
+	queue, ok := b.queues[lane]
+	if !ok {
+		return nil
+	}
+	stats, err := b.stats(ctx, queue)
+	if err != nil {
+		return nil
+	}
+
+	var reason error
+	switch {
+	case b.cfg.MinConsumers > 0 && stats.Consumers < b.cfg.MinConsumers:
+		reason = ErrNoConsumers
+	case b.cfg.MaxDepth > 0 && stats.Messages >= b.cfg.MaxDepth:
+		reason = ErrQueueBacklogged
+	default:
+		return nil
+	}
+	backpressureVars.Add(queue+".throttled", 1)
+	return &ThrottledError{Err: reason, RetryAfter: b.cfg.RetryAfter}
+}
+
+func (b *Backpressure) stats(ctx context.Context, queue string) (messaging.QueueStats, error) {
+	b.mu.Lock()
+	r, ok := b.readings[queue]
+	b.mu.Unlock()
+	if ok && time.Since(r.at) < b.cfg.CacheTTL {
+		return r.stats, nil
+	}
+
+	stats, err := b.inspector.QueueStats(ctx, queue)
+	if err != nil {
+		return messaging.QueueStats{}, err
+	}
+
+	b.mu.Lock()
+	b.readings[queue] = reading{stats: stats, at: time.Now()}
+	b.mu.Unlock()
+
+	gauge := func(key string, v int) {
+		n := new(expvar.Int)
+		n.Set(int64(v))
+		backpressureVars.Set(key, n)
+	}
+	gauge(queue+".messages", stats.Messages)
+	gauge(queue+".consumers", stats.Consumers)
+	return stats, nil
+}
@@ -5,10 +5,11 @@ import "github.com/shopspring/decimal"
 type TopUpRequest struct {
 	AccountNumber string `json:"accountNumber"`
 	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Reference     string `json:"reference,omitempty" binding:"omitempty,max=100"` // external reference, e.g. a bank transfer id
 }
 
 type TopUpResponse struct {
-	TransactionID uint64          `json:"transactionId"`
+	TransactionID string          `json:"transactionId"`
 	AccountNumber string          `json:"accountNumber"`
 	Amount        decimal.Decimal `json:"amount"`
 	BalanceBefore decimal.Decimal `json:"balanceBefore"`
@@ -0,0 +1,51 @@
+package payment
+
+import (
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+// Top-up lanes. Each lane is its own queue, and the worker weighs the
+// priority lane above the normal one.
+const (
+	LaneNormal   = "normal"
+	LanePriority = "priority"
+)
+
+// Routing keys of the lanes on topup.exchange
+var laneRoutingKeys = map[string]string{
+	LaneNormal:   "topup.created",
+	LanePriority: "topup.created.priority",
+}
+
+// PriorityPolicy assigns a top-up to a lane
+type PriorityPolicy interface {
+	Lane(account *entity.Account, amount decimal.Decimal) string
+}
+
+// ThresholdPolicy sends top-ups of at least Amount, or for accounts in one of
+// Tiers, to the priority lane. A zero Amount disables the amount rule.
+type ThresholdPolicy struct {
+	Amount decimal.Decimal
+	Tiers  map[string]bool
+}
+
+func NewThresholdPolicy(amount decimal.Decimal, tiers []string) *ThresholdPolicy {
+	p := &ThresholdPolicy{Amount: amount, Tiers: make(map[string]bool, len(tiers))}
+	for _, t := range tiers {
+		if t != "" {
+			p.Tiers[t] = true
+		}
+	}
+	return p
+}
+
+func (p *ThresholdPolicy) Lane(account *entity.Account, amount decimal.Decimal) string {
+	if p.Amount.IsPositive() && amount.GreaterThanOrEqual(p.Amount) {
+		return LanePriority
+	}
+	if account != nil && p.Tiers[account.Tier] {
+		return LanePriority
+	}
+	return LaneNormal
+}
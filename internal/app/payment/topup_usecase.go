@@ -1,46 +1,43 @@
 package payment
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
 	"github.com/junicochandra/golang-api-service/internal/app/payment/dto"
 	"github.com/junicochandra/golang-api-service/internal/domain/entity"
 	"github.com/junicochandra/golang-api-service/internal/domain/repository"
-	"github.com/junicochandra/golang-api-service/internal/infrastructure/service/rabbitmq"
 	"github.com/shopspring/decimal"
 )
 
 var (
-	ErrNotFound = errors.New("User not found")
+	ErrNotFound          = errors.New("User not found")
+	ErrAccountNotAllowed = errors.New("Account does not accept top-ups in its current status")
+	ErrQueueUnavailable  = errors.New("Top-up queue unavailable")
 )
 
-type TopUpMessage struct {
-	TransactionID string          `json:"transactionId"`
-	AccountNumber string          `json:"accountNumber"`
-	Amount        decimal.Decimal `json:"amount"`
-	Currency      string          `json:"currency"`
-	CreatedAt     time.Time       `json:"createdAt"`
-}
-
-func (m *TopUpMessage) Marshal() ([]byte, error) {
-	return json.Marshal(m)
-}
-
 type topUpUseCase struct {
 	accountRepo     repository.AccountRepository
 	transactionRepo repository.TransactionRepository
-	rabbitSvc       *rabbitmq.RabbitMQService
+	broker          messaging.BrokerPort
+	policy          PriorityPolicy
+	backpressure    *Backpressure
 }
 
-func NewTopUpUseCase(accountRepo repository.AccountRepository, transactionRepo repository.TransactionRepository, rabbitSvc *rabbitmq.RabbitMQService) TopUpUseCase {
+// NewTopUpUseCase publishes every top-up on the normal lane when policy is
+// nil, and accepts top-ups whatever the queue depth when backpressure is nil
+func NewTopUpUseCase(accountRepo repository.AccountRepository, transactionRepo repository.TransactionRepository, broker messaging.BrokerPort, policy PriorityPolicy, backpressure *Backpressure) TopUpUseCase {
 	return &topUpUseCase{
 		accountRepo:     accountRepo,
 		transactionRepo: transactionRepo,
-		rabbitSvc:       rabbitSvc,
+		broker:          broker,
+		policy:          policy,
+		backpressure:    backpressure,
 	}
 }
 
@@ -66,6 +63,23 @@ func (u *topUpUseCase) CreateTopUp(req *dto.TopUpRequest) (*dto.TopUpResponse, e
 	if account == nil {
 		return nil, ErrNotFound
 	}
+	if !account.CanCredit() {
+		return nil, ErrAccountNotAllowed
+	}
+
+	lane := LaneNormal
+	if u.policy != nil {
+		if l := u.policy.Lane(account, amountDecimal); laneRoutingKeys[l] != "" {
+			lane = l
+		}
+	}
+
+	// Refuse before a transaction is created while the lane is backed up
+	if u.backpressure != nil {
+		if err := u.backpressure.Check(context.Background(), lane); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create Transaction (pending)
 	txID := uuid.New().String()
@@ -78,37 +92,51 @@ func (u *topUpUseCase) CreateTopUp(req *dto.TopUpRequest) (*dto.TopUpResponse, e
 		Status:            "pending",
 		CreatedAt:         time.Now(),
 	}
+	if req.Reference != "" {
+		txn.Reference = &req.Reference
+	}
 
 	if err := u.transactionRepo.Create(txn); err != nil {
 		return nil, err
 	}
 
 	// Prepare message and publish
-	msg := &TopUpMessage{
+	env, err := contract.NewTopUpCreated(&contract.TopUpCreated{
 		TransactionID: txID,
 		AccountNumber: req.AccountNumber,
 		Amount:        amountDecimal,
 		Currency:      "IDR",
+		Reference:     req.Reference,
 		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		_ = u.transactionRepo.UpdateStatus(txID, "failed_marshal")
+		return nil, fmt.Errorf("failed to build message: %w", err)
 	}
-	body, err := msg.Marshal()
+	body, err := env.Marshal()
 	if err != nil {
 		_ = u.transactionRepo.UpdateStatus(txID, "failed_marshal")
 		return nil, fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	if u.rabbitSvc == nil {
-		_ = u.transactionRepo.UpdateStatus(txID, "failed_no_rabbit")
-		return nil, fmt.Errorf("rabbitmq service not initialized")
+	if u.broker == nil {
+		_ = u.transactionRepo.UpdateStatus(txID, "failed_no_broker")
+		return nil, fmt.Errorf("message broker not initialized")
 	}
 
-	if err := u.rabbitSvc.Publish("topup.exchange", "topup.created", body); err != nil {
-		_ = u.transactionRepo.UpdateStatus(txID, "failed_publish")
-		return nil, fmt.Errorf("failed to publish topup message: %w", err)
+	if err := u.broker.Publish(context.Background(), "topup.exchange", laneRoutingKeys[lane], messaging.Message{
+		ID:          env.MessageID,
+		ContentType: "application/json",
+		Body:        body,
+		Mandatory:   true,
+	}); err != nil {
+		_ = u.transactionRepo.UpdateStatus(txID, publishFailureStatus(err))
+		return nil, fmt.Errorf("%w: failed to publish topup message: %w", ErrQueueUnavailable, err)
 	}
 
 	// Success: return pending response (balance not yet updated)
 	return &dto.TopUpResponse{
+		TransactionID: txID,
 		AccountNumber: req.AccountNumber,
 		Amount:        amountDecimal,
 		BalanceBefore: account.Balance,
@@ -117,3 +145,19 @@ func (u *topUpUseCase) CreateTopUp(req *dto.TopUpRequest) (*dto.TopUpResponse, e
 		Status:        "pending",
 	}, nil
 }
+
+// publishFailureStatus maps a publish error to the transaction status. On a
+// confirm timeout the broker may still have the message, so the transaction is
+// flagged for reconciliation instead of failed.
+func publishFailureStatus(err error) string {
+	switch {
+	case errors.Is(err, messaging.ErrConfirmTimeout):
+		return "publish_unconfirmed"
+	case errors.Is(err, messaging.ErrUnroutable):
+		return "failed_unroutable"
+	case errors.Is(err, messaging.ErrNacked):
+		return "failed_nacked"
+	default:
+		return "failed_publish"
+	}
+}
@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/service/qris"
+	"github.com/shopspring/decimal"
+)
+
+type GenerateQRRequest struct {
+	AccountNumber        string `json:"accountNumber" binding:"required"`
+	MerchantName         string `json:"merchantName" binding:"required,max=25"`
+	MerchantCity         string `json:"merchantCity" binding:"required,max=15"`
+	MerchantCategoryCode string `json:"merchantCategoryCode" binding:"omitempty,len=4,numeric"`
+	PostalCode           string `json:"postalCode" binding:"omitempty,max=10"`
+	Amount               int64  `json:"amount" binding:"omitempty,gt=0"` // set for a dynamic QR
+	BillNumber           string `json:"billNumber" binding:"omitempty,max=25"`
+	TerminalLabel        string `json:"terminalLabel" binding:"omitempty,max=25"`
+}
+
+type GenerateQRResponse struct {
+	Payload string `json:"payload"`
+	Dynamic bool   `json:"dynamic"`
+}
+
+type ParseQRRequest struct {
+	Payload string `json:"payload" binding:"required"`
+}
+
+type ParseQRResponse struct {
+	qris.Payload
+	Dynamic               bool   `json:"dynamic"`
+	MerchantAccountNumber string `json:"merchantAccountNumber,omitempty"`
+}
+
+type PayQRRequest struct {
+	Payload       string `json:"payload" binding:"required"`
+	AccountNumber string `json:"accountNumber" binding:"required"` // payer
+	Amount        int64  `json:"amount" binding:"omitempty,gt=0"`  // required for a static QR
+}
+
+type PayQRResponse struct {
+	TransactionID         string          `json:"transactionId"`
+	AccountNumber         string          `json:"accountNumber"`
+	MerchantAccountNumber string          `json:"merchantAccountNumber"`
+	MerchantName          string          `json:"merchantName"`
+	Amount                decimal.Decimal `json:"amount"`
+	Currency              string          `json:"currency"`
+	Status                string          `json:"status"`
+}
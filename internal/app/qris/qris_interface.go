@@ -0,0 +1,10 @@
+package qris
+
+import "github.com/junicochandra/golang-api-service/internal/app/qris/dto"
+
+type QRISUseCase interface {
+	Generate(req *dto.GenerateQRRequest, email string) (*dto.GenerateQRResponse, error)
+	Parse(req *dto.ParseQRRequest) (*dto.ParseQRResponse, error)
+	Pay(req *dto.PayQRRequest, email string) (*dto.PayQRResponse, error)
+	RenderPNG(payload string, size int) ([]byte, error)
+}
@@ -0,0 +1,275 @@
+package qris
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/qris/dto"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+	emv "github.com/junicochandra/golang-api-service/internal/infrastructure/service/qris"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// MerchantGUID identifies merchant account templates issued by this service
+	MerchantGUID = "ID.CO.GOLANGAPI.WWW"
+	// merchantAccountTag is the template slot (26-51) used for our accounts
+	merchantAccountTag = "26"
+	defaultMCC         = "5499"
+	minPNGSize         = 128
+	maxPNGSize         = 1024
+	maxReferenceLabel  = 25
+)
+
+var (
+	ErrAccountNotFound  = errors.New("Account not found")
+	ErrForbidden        = errors.New("Account does not belong to this user")
+	ErrInvalidPayload   = errors.New("Invalid QR payload")
+	ErrUnknownMerchant  = errors.New("QR merchant is not served by this wallet")
+	ErrUnsupportedCurr  = errors.New("Only IDR QR payloads are supported")
+	ErrAmountRequired   = errors.New("Amount is required for a static QR")
+	ErrAmountMismatch   = errors.New("Amount does not match the dynamic QR")
+	ErrUnknownQR        = errors.New("Dynamic QR was not issued by this wallet")
+	ErrQRMismatch       = errors.New("QR does not match the one issued")
+	ErrSelfPayment      = errors.New("Cannot pay to the same account")
+	ErrInvalidImageSize = errors.New("Image size must be between 128 and 1024")
+)
+
+type qrisUseCase struct {
+	userRepo    repository.UserRepository
+	accountRepo repository.AccountRepository
+	codeRepo    repository.QRISCodeRepository
+}
+
+func NewQRISUseCase(userRepo repository.UserRepository, accountRepo repository.AccountRepository, codeRepo repository.QRISCodeRepository) QRISUseCase {
+	return &qrisUseCase{
+		userRepo:    userRepo,
+		accountRepo: accountRepo,
+		codeRepo:    codeRepo,
+	}
+}
+
+func (u *qrisUseCase) Generate(req *dto.GenerateQRRequest, email string) (*dto.GenerateQRResponse, error) {
+	account, err := u.ownedAccount(req.AccountNumber, email)
+	if err != nil {
+		return nil, err
+	}
+
+	mcc := req.MerchantCategoryCode
+	if mcc == "" {
+		mcc = defaultMCC
+	}
+
+	payload := &emv.Payload{
+		PointOfInitiation: emv.PointOfInitiationStatic,
+		MerchantAccounts: map[string]emv.MerchantAccount{
+			merchantAccountTag: {
+				GUID:       MerchantGUID,
+				PAN:        account.AccountNumber,
+				MerchantID: strconv.FormatUint(account.ID, 10),
+			},
+		},
+		MerchantCategoryCode: mcc,
+		Currency:             emv.CurrencyIDR,
+		CountryCode:          emv.CountryID,
+		MerchantName:         req.MerchantName,
+		MerchantCity:         req.MerchantCity,
+		PostalCode:           req.PostalCode,
+		BillNumber:           req.BillNumber,
+		TerminalLabel:        req.TerminalLabel,
+	}
+	if req.Amount > 0 {
+		payload.PointOfInitiation = emv.PointOfInitiationDynamic
+		payload.Amount = strconv.FormatInt(req.Amount, 10)
+		// Each single-use QR gets its own label, so two QRs for the same bill
+		// are paid independently
+		payload.ReferenceLabel = strings.ReplaceAll(uuid.New().String(), "-", "")[:maxReferenceLabel]
+	}
+
+	raw, err := emv.Encode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record what was issued; Pay trusts this, not the scanned amount
+	if payload.IsDynamic() {
+		if err := u.codeRepo.Create(&entity.QRISCode{
+			ReferenceLabel: payload.ReferenceLabel,
+			AccountNumber:  account.AccountNumber,
+			Amount:         decimal.NewFromInt(req.Amount),
+			CreatedAt:      time.Now(),
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &dto.GenerateQRResponse{Payload: raw, Dynamic: payload.IsDynamic()}, nil
+}
+
+func (u *qrisUseCase) Parse(req *dto.ParseQRRequest) (*dto.ParseQRResponse, error) {
+	payload, err := emv.Decode(req.Payload)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidPayload, err)
+	}
+
+	res := &dto.ParseQRResponse{Payload: *payload, Dynamic: payload.IsDynamic()}
+	if ma, ok := merchantAccount(payload); ok {
+		res.MerchantAccountNumber = ma.PAN
+	}
+	return res, nil
+}
+
+// Pay turns a scanned payload into a transfer from the payer to the merchant account
+func (u *qrisUseCase) Pay(req *dto.PayQRRequest, email string) (*dto.PayQRResponse, error) {
+	payload, err := emv.Decode(req.Payload)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidPayload, err)
+	}
+	if payload.Currency != emv.CurrencyIDR {
+		return nil, ErrUnsupportedCurr
+	}
+	ma, ok := merchantAccount(payload)
+	if !ok {
+		return nil, ErrUnknownMerchant
+	}
+
+	amount, err := payAmount(payload, req.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	// A dynamic QR is single use and must be paid exactly as issued
+	var qr *entity.QRISPayment
+	if payload.IsDynamic() {
+		if err := u.checkIssued(payload, ma, amount); err != nil {
+			return nil, err
+		}
+		qr = &entity.QRISPayment{ReferenceLabel: payload.ReferenceLabel, CreatedAt: time.Now()}
+	}
+
+	payer, err := u.ownedAccount(req.AccountNumber, email)
+	if err != nil {
+		return nil, err
+	}
+	if payer.AccountNumber == ma.PAN {
+		return nil, ErrSelfPayment
+	}
+
+	merchant, err := u.accountRepo.GetByAccountNumber(ma.PAN)
+	if err != nil {
+		return nil, err
+	}
+	if merchant == nil {
+		return nil, ErrUnknownMerchant
+	}
+
+	description := "QR payment to " + payload.MerchantName
+	txn := &entity.Transaction{
+		TransactionID:     uuid.New().String(),
+		Type:              "payment",
+		SenderAccountID:   payer.AccountNumber,
+		ReceiverAccountID: merchant.AccountNumber,
+		Amount:            amount,
+		Status:            "completed",
+		Description:       &description,
+		Payload:           &req.Payload,
+		CreatedAt:         time.Now(),
+	}
+	if payload.BillNumber != "" {
+		txn.Reference = &payload.BillNumber
+	}
+
+	if err := u.accountRepo.TransferTx(txn, qr); err != nil {
+		return nil, err
+	}
+
+	return &dto.PayQRResponse{
+		TransactionID:         txn.TransactionID,
+		AccountNumber:         payer.AccountNumber,
+		MerchantAccountNumber: merchant.AccountNumber,
+		MerchantName:          payload.MerchantName,
+		Amount:                amount,
+		Currency:              "IDR",
+		Status:                txn.Status,
+	}, nil
+}
+
+func (u *qrisUseCase) RenderPNG(payload string, size int) ([]byte, error) {
+	if size < minPNGSize || size > maxPNGSize {
+		return nil, ErrInvalidImageSize
+	}
+	// Only render payloads that would scan as valid
+	if _, err := emv.Decode(payload); err != nil {
+		return nil, errors.Join(ErrInvalidPayload, err)
+	}
+	return emv.RenderPNG(payload, size)
+}
+
+// checkIssued matches a scanned dynamic QR against the one Generate recorded.
+// The CRC is only a checksum, so an edited merchant or amount shows up here.
+func (u *qrisUseCase) checkIssued(payload *emv.Payload, ma emv.MerchantAccount, amount decimal.Decimal) error {
+	if payload.ReferenceLabel == "" {
+		return ErrUnknownQR
+	}
+	code, err := u.codeRepo.GetByReference(payload.ReferenceLabel)
+	if err != nil {
+		return err
+	}
+	if code == nil {
+		return ErrUnknownQR
+	}
+	if code.AccountNumber != ma.PAN || !code.Amount.Equal(amount) {
+		return ErrQRMismatch
+	}
+	return nil
+}
+
+func (u *qrisUseCase) ownedAccount(accountNumber, email string) (*entity.Account, error) {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	account, err := u.accountRepo.GetByAccountNumber(accountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+	if user == nil || account.UserID != user.ID {
+		return nil, ErrForbidden
+	}
+	return account, nil
+}
+
+// merchantAccount finds the template issued by this service, if any
+func merchantAccount(p *emv.Payload) (emv.MerchantAccount, bool) {
+	for _, ma := range p.MerchantAccounts {
+		if ma.GUID == MerchantGUID && ma.PAN != "" {
+			return ma, true
+		}
+	}
+	return emv.MerchantAccount{}, false
+}
+
+func payAmount(p *emv.Payload, requested int64) (decimal.Decimal, error) {
+	if p.Amount == "" {
+		if requested <= 0 {
+			return decimal.Zero, ErrAmountRequired
+		}
+		return decimal.NewFromInt(requested), nil
+	}
+
+	amount, err := decimal.NewFromString(p.Amount)
+	if err != nil || amount.Cmp(decimal.Zero) <= 0 {
+		return decimal.Zero, ErrInvalidPayload
+	}
+	if requested > 0 && !amount.Equal(decimal.NewFromInt(requested)) {
+		return decimal.Zero, ErrAmountMismatch
+	}
+	return amount, nil
+}
@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// TransactionStatusEvent is emitted by the worker whenever a transaction changes status
+type TransactionStatusEvent struct {
+	TransactionID string          `json:"transactionId"`
+	AccountNumber string          `json:"accountNumber"`
+	Amount        decimal.Decimal `json:"amount"`
+	Status        string          `json:"status"`
+	OccurredAt    time.Time       `json:"occurredAt"`
+}
+
+// IsFinal reports whether no further status change is expected
+func (e TransactionStatusEvent) IsFinal() bool {
+	return e.Status == "completed" || e.Status == "success" || strings.HasPrefix(e.Status, "failed")
+}
@@ -0,0 +1,81 @@
+package transaction
+
+import (
+	"sync"
+
+	"github.com/junicochandra/golang-api-service/internal/app/transaction/dto"
+)
+
+const subscriberBuffer = 16
+
+type subscriber struct {
+	filter func(dto.TransactionStatusEvent) bool
+	ch     chan dto.TransactionStatusEvent
+}
+
+// StatusHub fans status events out to in-process subscribers (SSE clients)
+type StatusHub struct {
+	mu     sync.RWMutex
+	nextID uint64
+	subs   map[uint64]*subscriber
+	closed bool
+}
+
+func NewStatusHub() *StatusHub {
+	return &StatusHub{subs: make(map[uint64]*subscriber)}
+}
+
+// Subscribe registers a filter and returns the event channel and a cancel func
+func (h *StatusHub) Subscribe(filter func(dto.TransactionStatusEvent) bool) (<-chan dto.TransactionStatusEvent, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &subscriber{filter: filter, ch: make(chan dto.TransactionStatusEvent, subscriberBuffer)}
+	if h.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+
+	id := h.nextID
+	h.nextID++
+	h.subs[id] = sub
+
+	return sub.ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subs[id]; ok {
+			delete(h.subs, id)
+			close(sub.ch)
+		}
+	}
+}
+
+// Close ends every subscription, so open streams finish during shutdown and
+// their clients reconnect to another instance
+func (h *StatusHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for id, sub := range h.subs {
+		delete(h.subs, id)
+		close(sub.ch)
+	}
+}
+
+// Publish delivers the event to matching subscribers. Slow subscribers miss
+// events instead of blocking the relay.
+func (h *StatusHub) Publish(evt dto.TransactionStatusEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, sub := range h.subs {
+		if !sub.filter(evt) {
+			continue
+		}
+		select {
+		case sub.ch <- evt:
+		default:
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package transaction
+
+import "github.com/junicochandra/golang-api-service/internal/app/transaction/dto"
+
+// Subscription is a live feed of status events. Current holds the status at
+// subscription time when watching a single transaction.
+type Subscription struct {
+	Current *dto.TransactionStatusEvent
+	Events  <-chan dto.TransactionStatusEvent
+	Cancel  func()
+}
+
+type TransactionStatusUseCase interface {
+	WatchTransaction(transactionID string, email string) (*Subscription, error)
+	WatchAccounts(email string) (*Subscription, error)
+}
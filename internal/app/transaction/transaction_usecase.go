@@ -0,0 +1,101 @@
+package transaction
+
+import (
+	"errors"
+
+	"github.com/junicochandra/golang-api-service/internal/app/transaction/dto"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+)
+
+var (
+	ErrNotFound     = errors.New("Transaction not found")
+	ErrUserNotFound = errors.New("User not found")
+	ErrForbidden    = errors.New("Transaction does not belong to this user")
+)
+
+type transactionStatusUseCase struct {
+	userRepo        repository.UserRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	hub             *StatusHub
+}
+
+func NewTransactionStatusUseCase(userRepo repository.UserRepository, accountRepo repository.AccountRepository, transactionRepo repository.TransactionRepository, hub *StatusHub) TransactionStatusUseCase {
+	return &transactionStatusUseCase{
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		hub:             hub,
+	}
+}
+
+func (u *transactionStatusUseCase) WatchTransaction(transactionID string, email string) (*Subscription, error) {
+	owned, err := u.ownedAccounts(email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscribe before reading the current status so no change is missed in between
+	events, cancel := u.hub.Subscribe(func(evt dto.TransactionStatusEvent) bool {
+		return evt.TransactionID == transactionID
+	})
+
+	txn, err := u.transactionRepo.GetByTransactionID(transactionID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if txn == nil {
+		cancel()
+		return nil, ErrNotFound
+	}
+	if !owned[txn.ReceiverAccountID] && !owned[txn.SenderAccountID] {
+		cancel()
+		return nil, ErrForbidden
+	}
+
+	return &Subscription{
+		Current: &dto.TransactionStatusEvent{
+			TransactionID: txn.TransactionID,
+			AccountNumber: txn.ReceiverAccountID,
+			Amount:        txn.Amount,
+			Status:        txn.Status,
+			OccurredAt:    txn.UpdatedAt,
+		},
+		Events: events,
+		Cancel: cancel,
+	}, nil
+}
+
+func (u *transactionStatusUseCase) WatchAccounts(email string) (*Subscription, error) {
+	owned, err := u.ownedAccounts(email)
+	if err != nil {
+		return nil, err
+	}
+
+	events, cancel := u.hub.Subscribe(func(evt dto.TransactionStatusEvent) bool {
+		return owned[evt.AccountNumber]
+	})
+	return &Subscription{Events: events, Cancel: cancel}, nil
+}
+
+func (u *transactionStatusUseCase) ownedAccounts(email string) (map[string]bool, error) {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	accounts, err := u.accountRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]bool, len(accounts))
+	for _, a := range accounts {
+		owned[a.AccountNumber] = true
+	}
+	return owned, nil
+}
@@ -3,7 +3,11 @@ package user
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
 	"github.com/junicochandra/golang-api-service/internal/app/user/dto"
 	"github.com/junicochandra/golang-api-service/internal/domain/entity"
 	"github.com/junicochandra/golang-api-service/internal/domain/repository"
@@ -17,10 +21,11 @@ var (
 
 type userUseCase struct {
 	userRepo repository.UserRepository
+	events   messaging.EventPublisher
 }
 
-func NewUserUseCase(userRepo repository.UserRepository) UserUseCase {
-	return &userUseCase{userRepo: userRepo}
+func NewUserUseCase(userRepo repository.UserRepository, events messaging.EventPublisher) UserUseCase {
+	return &userUseCase{userRepo: userRepo, events: events}
 }
 
 func (u *userUseCase) GetAll() ([]dto.UserListResponse, error) {
@@ -84,7 +89,17 @@ func (u *userUseCase) Create(req *dto.UserCreateRequest) error {
 		Password: string(hashed),
 	}
 
-	return u.userRepo.Create(user)
+	if err := u.userRepo.Create(user); err != nil {
+		return err
+	}
+
+	u.events.Publish(contract.TypeUserRegistered, &contract.UserRegistered{
+		UserID:       user.ID,
+		Name:         user.Name,
+		Email:        user.Email,
+		RegisteredAt: time.Now(),
+	}, strconv.FormatUint(user.ID, 10))
+	return nil
 }
 
 func (u *userUseCase) Update(id uint64, req *dto.UserUpdateRequest) (*dto.UserUpdateResponse, error) {
@@ -133,5 +148,14 @@ func (u *userUseCase) Delete(id uint64) error {
 		return ErrNotFound
 	}
 
-	return u.userRepo.Delete(id)
+	if err := u.userRepo.Delete(id); err != nil {
+		return err
+	}
+
+	u.events.Publish(contract.TypeUserDeleted, &contract.UserDeleted{
+		UserID:    find.ID,
+		Email:     find.Email,
+		DeletedAt: time.Now(),
+	}, strconv.FormatUint(find.ID, 10))
+	return nil
 }
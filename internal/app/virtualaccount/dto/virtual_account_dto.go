@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type IssueVARequest struct {
+	AccountNumber string `json:"accountNumber" binding:"required"`
+	BankCode      string `json:"bankCode" binding:"required"`
+}
+
+type VAResponse struct {
+	VANumber      string     `json:"vaNumber"`
+	BankCode      string     `json:"bankCode"`
+	AccountNumber string     `json:"accountNumber"`
+	Status        string     `json:"status"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+}
+
+// CreditNotification is sent by the bank when money arrives on a virtual account
+type CreditNotification struct {
+	VANumber      string     `json:"vaNumber" binding:"required"`
+	Amount        int64      `json:"amount" binding:"required,gt=0"`
+	BankReference string     `json:"bankReference" binding:"required,max=64"`
+	PaidAt        *time.Time `json:"paidAt"`
+}
+
+type SimulateCreditRequest struct {
+	VANumber string `json:"vaNumber" binding:"required"`
+	Amount   int64  `json:"amount" binding:"required,gt=0"`
+}
+
+type CreditResponse struct {
+	BankReference string          `json:"bankReference"`
+	VANumber      string          `json:"vaNumber"`
+	AccountNumber string          `json:"accountNumber"`
+	Amount        decimal.Decimal `json:"amount"`
+	TransactionID string          `json:"transactionId"`
+	Status        string          `json:"status"`
+	Duplicate     bool            `json:"duplicate"`
+}
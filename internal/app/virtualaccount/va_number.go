@@ -0,0 +1,60 @@
+package virtualaccount
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// vaLength is the total number of digits, check digit included
+const vaLength = 16
+
+// Bank prefixes assigned to this service by each partner bank
+var bankPrefixes = map[string]string{
+	"BCA":     "39358",
+	"BNI":     "8808",
+	"BRI":     "26215",
+	"MANDIRI": "89608",
+	"PERMATA": "8625",
+}
+
+// vaNumber builds prefix + zero-padded user id + Luhn check digit. The number
+// depends only on bank and user, so a user always gets the same VA per bank.
+func vaNumber(prefix string, userID uint64) (string, error) {
+	width := vaLength - len(prefix) - 1
+	body := strconv.FormatUint(userID, 10)
+	if len(body) > width {
+		return "", fmt.Errorf("user id %d does not fit in a %s virtual account", userID, prefix)
+	}
+	partial := fmt.Sprintf("%s%0*s", prefix, width, body)
+	return partial + strconv.Itoa(luhnDigit(partial)), nil
+}
+
+// validVANumber checks length, digits and the check digit
+func validVANumber(va string) bool {
+	if len(va) != vaLength {
+		return false
+	}
+	for _, r := range va {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return luhnDigit(va[:vaLength-1]) == int(va[vaLength-1]-'0')
+}
+
+func luhnDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return (10 - sum%10) % 10
+}
@@ -0,0 +1,12 @@
+package virtualaccount
+
+import "github.com/junicochandra/golang-api-service/internal/app/virtualaccount/dto"
+
+type VirtualAccountUseCase interface {
+	Issue(req *dto.IssueVARequest, email string) (*dto.VAResponse, error)
+	List(email string) ([]dto.VAResponse, error)
+	Lookup(vaNumber string, email string) (*dto.VAResponse, error)
+	Deactivate(vaNumber string, email string) (*dto.VAResponse, error)
+	HandleCredit(req *dto.CreditNotification) (*dto.CreditResponse, error)
+	SimulateCredit(req *dto.SimulateCreditRequest) (*dto.CreditResponse, error)
+}
@@ -0,0 +1,354 @@
+package virtualaccount
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/payment"
+	paymentDto "github.com/junicochandra/golang-api-service/internal/app/payment/dto"
+	"github.com/junicochandra/golang-api-service/internal/app/virtualaccount/dto"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	creditReceived  = "received"
+	creditProcessed = "processed"
+	creditFailed    = "failed"
+
+	// creditStaleAfter is how long a received credit without a top-up counts
+	// as in progress; after that a retry assumes the first attempt died
+	creditStaleAfter = time.Minute
+)
+
+var (
+	ErrNotFound          = errors.New("Virtual account not found")
+	ErrAccountNotFound   = errors.New("Account not found")
+	ErrForbidden         = errors.New("Virtual account does not belong to this user")
+	ErrUnknownBank       = errors.New("Unknown bank code")
+	ErrInvalidVANumber   = errors.New("Invalid virtual account number")
+	ErrInactive          = errors.New("Virtual account is inactive")
+	ErrAlreadyInactive   = errors.New("Virtual account is already inactive")
+	ErrCreditInProgress  = errors.New("Credit notification is already being processed")
+	ErrCreditMismatch    = errors.New("Credit notification does not match the recorded credit")
+	ErrSimulatorDisabled = errors.New("Bank feed simulator is disabled")
+)
+
+type virtualAccountUseCase struct {
+	vaRepo          repository.VirtualAccountRepository
+	userRepo        repository.UserRepository
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+	topUpUC         payment.TopUpUseCase
+	simulator       bool
+}
+
+func NewVirtualAccountUseCase(vaRepo repository.VirtualAccountRepository, userRepo repository.UserRepository, accountRepo repository.AccountRepository, transactionRepo repository.TransactionRepository, topUpUC payment.TopUpUseCase, simulator bool) VirtualAccountUseCase {
+	return &virtualAccountUseCase{
+		vaRepo:          vaRepo,
+		userRepo:        userRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		topUpUC:         topUpUC,
+		simulator:       simulator,
+	}
+}
+
+// Issue returns the user's VA for the bank, creating or reactivating it as needed
+func (u *virtualAccountUseCase) Issue(req *dto.IssueVARequest, email string) (*dto.VAResponse, error) {
+	prefix, ok := bankPrefixes[req.BankCode]
+	if !ok {
+		return nil, ErrUnknownBank
+	}
+
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrForbidden
+	}
+
+	account, err := u.accountRepo.GetByAccountNumber(req.AccountNumber)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+	if account.UserID != user.ID {
+		return nil, ErrForbidden
+	}
+
+	va, err := u.vaRepo.GetByUserAndBank(user.ID, req.BankCode)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if va != nil {
+		va.AccountNumber = account.AccountNumber
+		va.Status = entity.VirtualAccountActive
+		va.DeactivatedAt = nil
+		va.UpdatedAt = now
+		if err := u.vaRepo.Update(va); err != nil {
+			return nil, err
+		}
+		return toVAResponse(va), nil
+	}
+
+	number, err := vaNumber(prefix, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	va = &entity.VirtualAccount{
+		VANumber:      number,
+		BankCode:      req.BankCode,
+		UserID:        user.ID,
+		AccountNumber: account.AccountNumber,
+		Status:        entity.VirtualAccountActive,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := u.vaRepo.Create(va); err != nil {
+		return nil, err
+	}
+	return toVAResponse(va), nil
+}
+
+func (u *virtualAccountUseCase) List(email string) ([]dto.VAResponse, error) {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrForbidden
+	}
+
+	vas, err := u.vaRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dto.VAResponse, 0, len(vas))
+	for i := range vas {
+		res = append(res, *toVAResponse(&vas[i]))
+	}
+	return res, nil
+}
+
+func (u *virtualAccountUseCase) Lookup(vaNumber string, email string) (*dto.VAResponse, error) {
+	va, err := u.ownedVA(vaNumber, email)
+	if err != nil {
+		return nil, err
+	}
+	return toVAResponse(va), nil
+}
+
+func (u *virtualAccountUseCase) Deactivate(vaNumber string, email string) (*dto.VAResponse, error) {
+	va, err := u.ownedVA(vaNumber, email)
+	if err != nil {
+		return nil, err
+	}
+	if va.Status == entity.VirtualAccountInactive {
+		return nil, ErrAlreadyInactive
+	}
+
+	now := time.Now()
+	va.Status = entity.VirtualAccountInactive
+	va.DeactivatedAt = &now
+	va.UpdatedAt = now
+	if err := u.vaRepo.Update(va); err != nil {
+		return nil, err
+	}
+	return toVAResponse(va), nil
+}
+
+// HandleCredit turns a bank credit notification into a top-up. Notifications
+// are idempotent on BankReference and must repeat the recorded VA and amount.
+// A retry of a credit left "received" or "failed" first links the top-up an
+// earlier attempt created; only when there is none does it claim the credit
+// and create one, so concurrent retries credit the account once.
+func (u *virtualAccountUseCase) HandleCredit(req *dto.CreditNotification) (*dto.CreditResponse, error) {
+	if !validVANumber(req.VANumber) {
+		return nil, ErrInvalidVANumber
+	}
+
+	credit, err := u.vaRepo.GetCreditByReference(req.BankReference)
+	if err != nil {
+		return nil, err
+	}
+	if credit != nil && (credit.VANumber != req.VANumber || !credit.Amount.Equal(decimal.NewFromInt(req.Amount))) {
+		return nil, ErrCreditMismatch
+	}
+
+	va, err := u.vaRepo.GetByNumber(req.VANumber)
+	if err != nil {
+		return nil, err
+	}
+	if va == nil {
+		return nil, ErrNotFound
+	}
+
+	if credit != nil {
+		if credit.Status == creditProcessed {
+			return toCreditResponse(credit, va, true), nil
+		}
+		if res, err := u.resumeCredit(credit, va); res != nil || err != nil {
+			return res, err
+		}
+	}
+
+	if va.Status != entity.VirtualAccountActive {
+		return nil, ErrInactive
+	}
+
+	if credit == nil {
+		now := time.Now()
+		credit = &entity.VirtualAccountCredit{
+			BankReference: req.BankReference,
+			VANumber:      req.VANumber,
+			Amount:        decimal.NewFromInt(req.Amount),
+			Status:        creditReceived,
+			PaidAt:        req.PaidAt,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		// The unique bank reference rejects a concurrent duplicate here
+		if err := u.vaRepo.CreateCredit(credit); err != nil {
+			return nil, fmt.Errorf("failed to record credit %s: %w", req.BankReference, err)
+		}
+	}
+
+	res, err := u.topUpUC.CreateTopUp(&paymentDto.TopUpRequest{
+		AccountNumber: va.AccountNumber,
+		Amount:        credit.Amount.IntPart(),
+		Reference:     creditReference(credit.BankReference),
+	})
+	if err != nil {
+		credit.Status = creditFailed
+		credit.UpdatedAt = time.Now()
+		_ = u.vaRepo.UpdateCredit(credit)
+		return nil, err
+	}
+
+	if err := u.markProcessed(credit, res.TransactionID); err != nil {
+		return nil, err
+	}
+	return toCreditResponse(credit, va, false), nil
+}
+
+// resumeCredit handles a retry of a credit that is not yet processed. It
+// returns a response when an earlier top-up can be linked, ErrCreditInProgress
+// while another attempt may still deliver, and nil, nil once this retry has
+// claimed the credit and should create the top-up itself.
+func (u *virtualAccountUseCase) resumeCredit(credit *entity.VirtualAccountCredit, va *entity.VirtualAccount) (*dto.CreditResponse, error) {
+	trx, err := u.transactionRepo.GetLatestByReference(creditReference(credit.BankReference))
+	if err != nil {
+		return nil, err
+	}
+	if trx != nil {
+		switch {
+		case trx.Status == "publish_unconfirmed":
+			// The broker may still deliver it; reconciliation settles it, not a retry
+			return nil, ErrCreditInProgress
+		case !strings.HasPrefix(trx.Status, "failed"):
+			// The top-up went out but linking it did not; finish that
+			if err := u.markProcessed(credit, trx.TransactionID); err != nil {
+				return nil, err
+			}
+			return toCreditResponse(credit, va, true), nil
+		}
+	}
+	if credit.Status == creditReceived && time.Since(credit.UpdatedAt) < creditStaleAfter {
+		return nil, ErrCreditInProgress
+	}
+
+	status, updatedAt := credit.Status, credit.UpdatedAt
+	credit.Status = creditReceived
+	credit.UpdatedAt = time.Now()
+	claimed, err := u.vaRepo.ClaimCredit(credit, status, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrCreditInProgress
+	}
+	return nil, nil
+}
+
+func (u *virtualAccountUseCase) markProcessed(credit *entity.VirtualAccountCredit, transactionID string) error {
+	credit.TransactionID = &transactionID
+	credit.Status = creditProcessed
+	credit.UpdatedAt = time.Now()
+	return u.vaRepo.UpdateCredit(credit)
+}
+
+// creditReference is the top-up reference of a bank credit
+func creditReference(bankReference string) string {
+	return "va:" + bankReference
+}
+
+// SimulateCredit plays the bank for local testing
+func (u *virtualAccountUseCase) SimulateCredit(req *dto.SimulateCreditRequest) (*dto.CreditResponse, error) {
+	if !u.simulator {
+		return nil, ErrSimulatorDisabled
+	}
+
+	now := time.Now()
+	return u.HandleCredit(&dto.CreditNotification{
+		VANumber:      req.VANumber,
+		Amount:        req.Amount,
+		BankReference: "SIM-" + uuid.New().String(),
+		PaidAt:        &now,
+	})
+}
+
+func (u *virtualAccountUseCase) ownedVA(vaNumber, email string) (*entity.VirtualAccount, error) {
+	user, err := u.userRepo.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	va, err := u.vaRepo.GetByNumber(vaNumber)
+	if err != nil {
+		return nil, err
+	}
+	if va == nil {
+		return nil, ErrNotFound
+	}
+	if user == nil || va.UserID != user.ID {
+		return nil, ErrForbidden
+	}
+	return va, nil
+}
+
+func toVAResponse(va *entity.VirtualAccount) *dto.VAResponse {
+	return &dto.VAResponse{
+		VANumber:      va.VANumber,
+		BankCode:      va.BankCode,
+		AccountNumber: va.AccountNumber,
+		Status:        va.Status,
+		CreatedAt:     va.CreatedAt,
+		DeactivatedAt: va.DeactivatedAt,
+	}
+}
+
+func toCreditResponse(credit *entity.VirtualAccountCredit, va *entity.VirtualAccount, duplicate bool) *dto.CreditResponse {
+	res := &dto.CreditResponse{
+		BankReference: credit.BankReference,
+		VANumber:      credit.VANumber,
+		AccountNumber: va.AccountNumber,
+		Amount:        credit.Amount,
+		Status:        credit.Status,
+		Duplicate:     duplicate,
+	}
+	if credit.TransactionID != nil {
+		res.TransactionID = *credit.TransactionID
+	}
+	return res
+}
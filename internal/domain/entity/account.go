@@ -6,11 +6,36 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+const (
+	AccountStatusActive  = "active"
+	AccountStatusFrozen  = "frozen"
+	AccountStatusBlocked = "blocked"
+	AccountStatusClosed  = "closed"
+)
+
+const AccountTierStandard = "standard"
+
 type Account struct {
-	ID            uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID        uint64          `gorm:"not null" json:"userId"`
-	AccountNumber string          `gorm:"not null" json:"accountNumber"`
-	Balance       decimal.Decimal `gorm:"type:decimal(18,2);not null;default:0.00" json:"balance"`
-	Currency      string          `gorm:"size:10;not null;default:'IDR'" json:"currency"`
-	UpdatedAt     time.Time       `json:"updatedAt"`
+	ID              uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID          uint64          `gorm:"not null" json:"userId"`
+	AccountNumber   string          `gorm:"not null" json:"accountNumber"`
+	Balance         decimal.Decimal `gorm:"type:decimal(18,2);not null;default:0.00" json:"balance"`
+	Currency        string          `gorm:"size:10;not null;default:'IDR'" json:"currency"`
+	Status          string          `gorm:"size:20;not null;default:'active'" json:"status"`
+	Tier            string          `gorm:"size:20;not null;default:'standard'" json:"tier"`
+	StatusReason    *string         `gorm:"size:50" json:"statusReason,omitempty"`
+	StatusChangedAt *time.Time      `json:"statusChangedAt,omitempty"`
+	StatusChangedBy *string         `gorm:"size:255" json:"statusChangedBy,omitempty"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+}
+
+// CanDebit reports whether money may leave the account.
+func (a *Account) CanDebit() bool {
+	return a.Status == "" || a.Status == AccountStatusActive
+}
+
+// CanCredit reports whether money may enter the account. A frozen account
+// still receives credits; blocked and closed accounts accept nothing.
+func (a *Account) CanCredit() bool {
+	return a.Status == "" || a.Status == AccountStatusActive || a.Status == AccountStatusFrozen
 }
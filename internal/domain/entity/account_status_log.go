@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type AccountStatusLog struct {
+	ID            uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	AccountNumber string    `gorm:"size:30;not null;index" json:"accountNumber"`
+	FromStatus    string    `gorm:"size:20;not null" json:"fromStatus"`
+	ToStatus      string    `gorm:"size:20;not null" json:"toStatus"`
+	ReasonCode    string    `gorm:"size:50;not null" json:"reasonCode"`
+	Note          *string   `gorm:"type:text" json:"note,omitempty"`
+	Actor         string    `gorm:"size:255;not null" json:"actor"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
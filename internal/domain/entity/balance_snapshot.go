@@ -0,0 +1,19 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceSnapshot holds the end-of-day balance of an account. AsOf is the
+// exact cut-off the balance was computed for.
+type BalanceSnapshot struct {
+	ID            uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	AccountNumber string          `gorm:"size:30;not null;uniqueIndex:uni_snapshot_account_date" json:"accountNumber"`
+	SnapshotDate  string          `gorm:"size:10;not null;uniqueIndex:uni_snapshot_account_date" json:"snapshotDate"` // YYYY-MM-DD
+	AsOf          time.Time       `gorm:"not null;index" json:"asOf"`
+	Balance       decimal.Decimal `gorm:"type:decimal(18,2);not null" json:"balance"`
+	Currency      string          `gorm:"size:10;not null;default:'IDR'" json:"currency"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// DeadLetterAuditLog records every operator action on a dead-letter queue
+type DeadLetterAuditLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	Queue      string    `gorm:"size:100;not null;index" json:"queue"`
+	Action     string    `gorm:"size:20;not null" json:"action"`
+	MessageIDs string    `gorm:"type:text" json:"messageIds"`
+	Detail     *string   `gorm:"type:text" json:"detail,omitempty"`
+	Result     string    `gorm:"size:20;not null" json:"result"`
+	Error      *string   `gorm:"type:text" json:"error,omitempty"`
+	Actor      string    `gorm:"size:255;not null" json:"actor"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
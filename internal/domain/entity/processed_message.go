@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// ProcessedMessage is the worker inbox: one row per message whose side
+// effects are committed, written in the same transaction as those effects
+type ProcessedMessage struct {
+	Consumer    string    `gorm:"primaryKey;size:50" json:"consumer"`
+	MessageID   string    `gorm:"primaryKey;size:100" json:"messageId"`
+	ProcessedAt time.Time `gorm:"not null;index" json:"processedAt"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// QRISCode is a dynamic QR as issued, keyed by its reference label. A scanned
+// payload is paid only if its merchant account and amount match this record,
+// since the CRC in the payload does not stop anyone from editing it.
+type QRISCode struct {
+	ReferenceLabel string          `gorm:"primaryKey;size:25" json:"referenceLabel"`
+	AccountNumber  string          `gorm:"size:30;not null" json:"accountNumber"`
+	Amount         decimal.Decimal `gorm:"type:decimal(18,2);not null" json:"amount"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// QRISPayment marks a dynamic QR as paid. It is keyed by the QR's reference
+// label and written in the payment's transaction, so a single-use QR is paid
+// at most once.
+type QRISPayment struct {
+	ReferenceLabel string    `gorm:"primaryKey;size:25" json:"referenceLabel"`
+	TransactionID  string    `gorm:"size:50;not null" json:"transactionId"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
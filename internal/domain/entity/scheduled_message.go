@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+const (
+	ScheduledStatusPending   = "pending"
+	ScheduledStatusCancelled = "cancelled"
+	ScheduledStatusReleased  = "released"
+	ScheduledStatusFailed    = "failed"
+)
+
+// ScheduledMessage tracks a delayed publish while it waits in a staging queue,
+// so any instance can cancel it before it is released
+type ScheduledMessage struct {
+	MessageID  string    `gorm:"primaryKey;size:100" json:"messageId"`
+	Exchange   string    `gorm:"size:100;not null" json:"exchange"`
+	RoutingKey string    `gorm:"size:255;not null" json:"routingKey"`
+	DueAt      time.Time `gorm:"not null;index" json:"dueAt"`
+	Status     string    `gorm:"size:20;not null;index" json:"status"` // pending | cancelled | released | failed
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
@@ -17,6 +17,7 @@ type Transaction struct {
 	Reference         *string         `json:"reference" db:"reference"`     // nullable
 	Description       *string         `json:"description" db:"description"` // nullable
 	Payload           *string         `json:"payload" db:"payload"`         // nullable (text)
+	SettledAt         *time.Time      `json:"settledAt" db:"settled_at"`    // set once, when the balance moves
 	CreatedAt         time.Time       `json:"createdAt" db:"created_at"`
 	UpdatedAt         time.Time       `json:"updatedAt" db:"updated_at"`
 }
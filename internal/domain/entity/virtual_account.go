@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	VirtualAccountActive   = "active"
+	VirtualAccountInactive = "inactive"
+)
+
+// VirtualAccount is a bank-facing number that routes incoming transfers to a wallet account
+type VirtualAccount struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	VANumber      string     `gorm:"column:va_number;size:20;not null;uniqueIndex" json:"vaNumber"`
+	BankCode      string     `gorm:"size:20;not null;uniqueIndex:uni_va_user_bank" json:"bankCode"`
+	UserID        uint64     `gorm:"not null;uniqueIndex:uni_va_user_bank" json:"userId"`
+	AccountNumber string     `gorm:"size:30;not null" json:"accountNumber"`
+	Status        string     `gorm:"size:20;not null;default:'active'" json:"status"`
+	DeactivatedAt *time.Time `json:"deactivatedAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// VirtualAccountCredit records an inbound bank credit notification. BankReference
+// is unique so a notification delivered twice is only credited once.
+type VirtualAccountCredit struct {
+	ID            uint64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	BankReference string          `gorm:"size:64;not null;uniqueIndex" json:"bankReference"`
+	VANumber      string          `gorm:"column:va_number;size:20;not null;index" json:"vaNumber"`
+	Amount        decimal.Decimal `gorm:"type:decimal(18,2);not null" json:"amount"`
+	TransactionID *string         `gorm:"size:50" json:"transactionId,omitempty"`
+	Status        string          `gorm:"size:20;not null" json:"status"` // received | processed | failed
+	PaidAt        *time.Time      `json:"paidAt,omitempty"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
@@ -1,8 +1,25 @@
 package repository
 
-import "github.com/junicochandra/golang-api-service/internal/domain/entity"
+import (
+	"errors"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrInsufficientFunds  = errors.New("Insufficient funds")
+	ErrAccountUnavailable = errors.New("Account is not available for this transaction")
+	ErrStatusChanged      = errors.New("Account status changed concurrently")
+	ErrBalanceNotZero     = errors.New("Account balance is not zero")
+	ErrQRAlreadyPaid      = errors.New("QR has already been paid")
+)
 
 type AccountRepository interface {
+	GetAll() ([]entity.Account, error)
+	GetByUserID(userID uint64) ([]entity.Account, error)
 	GetByAccountNumber(accountNumber string) (*entity.Account, error)
-	UpdateBalanceTx(account *entity.Account) error
+	CreditTx(accountNumber string, amount decimal.Decimal, transactionID string, inbox *entity.ProcessedMessage) error
+	UpdateStatusTx(account *entity.Account, log *entity.AccountStatusLog) error
+	TransferTx(txn *entity.Transaction, qr *entity.QRISPayment) error
 }
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+)
+
+type BalanceSnapshotRepository interface {
+	Upsert(snapshot *entity.BalanceSnapshot) error
+	GetLatestAtOrBefore(accountNumber string, at time.Time) (*entity.BalanceSnapshot, error)
+}
@@ -0,0 +1,7 @@
+package repository
+
+import "github.com/junicochandra/golang-api-service/internal/domain/entity"
+
+type DeadLetterAuditRepository interface {
+	Create(log *entity.DeadLetterAuditLog) error
+}
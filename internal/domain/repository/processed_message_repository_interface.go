@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrAlreadyProcessed is returned when the inbox already holds a message
+var ErrAlreadyProcessed = errors.New("Message already processed")
+
+type ProcessedMessageRepository interface {
+	DeleteBefore(t time.Time) (int64, error)
+}
@@ -0,0 +1,8 @@
+package repository
+
+import "github.com/junicochandra/golang-api-service/internal/domain/entity"
+
+type QRISCodeRepository interface {
+	Create(code *entity.QRISCode) error
+	GetByReference(referenceLabel string) (*entity.QRISCode, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+)
+
+type ScheduledMessageRepository interface {
+	Create(msg *entity.ScheduledMessage) error
+	GetByMessageID(messageID string) (*entity.ScheduledMessage, error)
+	// CompareAndSetStatus moves the message from one status to another and
+	// reports whether it was in the from status
+	CompareAndSetStatus(messageID, from, to string) (bool, error)
+	DeleteSettledBefore(t time.Time) (int64, error)
+}
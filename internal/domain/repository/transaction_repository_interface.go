@@ -1,11 +1,15 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/junicochandra/golang-api-service/internal/domain/entity"
 )
 
 type TransactionRepository interface {
 	Create(txn *entity.Transaction) error
 	GetByTransactionID(transactionID string) (*entity.Transaction, error)
+	GetLatestByReference(reference string) (*entity.Transaction, error)
 	UpdateStatus(transactionId string, status string) error
+	ListSettledByAccountBetween(accountNumber string, from, to time.Time) ([]entity.Transaction, error)
 }
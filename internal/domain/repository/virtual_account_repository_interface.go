@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+)
+
+type VirtualAccountRepository interface {
+	Create(va *entity.VirtualAccount) error
+	Update(va *entity.VirtualAccount) error
+	GetByNumber(vaNumber string) (*entity.VirtualAccount, error)
+	GetByUserAndBank(userID uint64, bankCode string) (*entity.VirtualAccount, error)
+	GetByUserID(userID uint64) ([]entity.VirtualAccount, error)
+
+	CreateCredit(credit *entity.VirtualAccountCredit) error
+	UpdateCredit(credit *entity.VirtualAccountCredit) error
+	GetCreditByReference(bankReference string) (*entity.VirtualAccountCredit, error)
+	// ClaimCredit saves credit's status and updated_at only if the stored row
+	// still has the given status and updated_at; false means another retry won
+	ClaimCredit(credit *entity.VirtualAccountCredit, status string, updatedAt time.Time) (bool, error)
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	usecase "github.com/junicochandra/golang-api-service/internal/app/account"
+	"github.com/junicochandra/golang-api-service/internal/app/account/dto"
+)
+
+type AccountHandler struct {
+	usecase usecase.AccountUseCase
+}
+
+func NewAccountHandler(uc usecase.AccountUseCase) *AccountHandler {
+	return &AccountHandler{usecase: uc}
+}
+
+// @Tags Accounts
+// @Summary Freeze an account
+// @Description Freeze an account so no debits are allowed. Credits are still accepted. Admins only.
+// @Router /accounts/{accountNumber}/freeze [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param accountNumber path string true "Account number"
+// @Param request body dto.AccountStatusRequest true "Reason for the status change"
+// @Success 200 {object} dto.AccountStatusResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+func (h *AccountHandler) Freeze(c *gin.Context) {
+	h.changeStatus(c, h.usecase.Freeze)
+}
+
+// @Tags Accounts
+// @Summary Block an account
+// @Description Block an account so no debits or credits are allowed. Admins only.
+// @Router /accounts/{accountNumber}/block [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param accountNumber path string true "Account number"
+// @Param request body dto.AccountStatusRequest true "Reason for the status change"
+// @Success 200 {object} dto.AccountStatusResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+func (h *AccountHandler) Block(c *gin.Context) {
+	h.changeStatus(c, h.usecase.Block)
+}
+
+// @Tags Accounts
+// @Summary Close an account
+// @Description Close an account permanently. The balance must be zero. Admins only.
+// @Router /accounts/{accountNumber}/close [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param accountNumber path string true "Account number"
+// @Param request body dto.AccountStatusRequest true "Reason for the status change"
+// @Success 200 {object} dto.AccountStatusResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+func (h *AccountHandler) Close(c *gin.Context) {
+	h.changeStatus(c, h.usecase.Close)
+}
+
+// @Tags Accounts
+// @Summary Reactivate an account
+// @Description Lift a freeze or block and return the account to active. Admins only.
+// @Router /accounts/{accountNumber}/activate [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param accountNumber path string true "Account number"
+// @Param request body dto.AccountStatusRequest true "Reason for the status change"
+// @Success 200 {object} dto.AccountStatusResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+func (h *AccountHandler) Activate(c *gin.Context) {
+	h.changeStatus(c, h.usecase.Activate)
+}
+
+func (h *AccountHandler) changeStatus(c *gin.Context, action func(string, *dto.AccountStatusRequest, string) (*dto.AccountStatusResponse, error)) {
+	var req dto.AccountStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := action(c.Param("accountNumber"), &req, currentEmail(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrInvalidReasonCode), errors.Is(err, usecase.ErrActorRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrInvalidTransition), errors.Is(err, usecase.ErrNonZeroBalance):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	usecase "github.com/junicochandra/golang-api-service/internal/app/balance"
+)
+
+type BalanceHandler struct {
+	usecase usecase.BalanceUseCase
+}
+
+func NewBalanceHandler(uc usecase.BalanceUseCase) *BalanceHandler {
+	return &BalanceHandler{usecase: uc}
+}
+
+// @Tags Accounts
+// @Summary Get account balance at a point in time
+// @Description Get the balance of one of your accounts at the given time. A plain date (YYYY-MM-DD) returns the end-of-day balance. Defaults to now.
+// @Router /accounts/{accountNumber}/balance [get]
+// @Security BearerAuth
+// @Produce json
+// @Param accountNumber path string true "Account number"
+// @Param at query string false "RFC3339 timestamp or YYYY-MM-DD"
+// @Success 200 {object} dto.BalanceAtResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+func (h *BalanceHandler) GetBalance(c *gin.Context) {
+	at, err := parseAt(c.Query("at"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid at, use RFC3339 or YYYY-MM-DD"})
+		return
+	}
+
+	res, err := h.usecase.GetBalanceAt(c.Param("accountNumber"), at, currentEmail(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrNotFound), errors.Is(err, usecase.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, usecase.ErrFutureTime):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func parseAt(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// End of the given day
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	usecase "github.com/junicochandra/golang-api-service/internal/app/deadletter"
+	"github.com/junicochandra/golang-api-service/internal/app/deadletter/dto"
+)
+
+type DeadLetterHandler struct {
+	usecase usecase.DeadLetterUseCase
+}
+
+func NewDeadLetterHandler(uc usecase.DeadLetterUseCase) *DeadLetterHandler {
+	return &DeadLetterHandler{usecase: uc}
+}
+
+// @Tags Admin
+// @Summary Inspect a dead-letter queue
+// @Description List parked messages with their decoded body and failure headers. Sealed bodies are only decrypted for DLQ_DECRYPT_EMAILS. Messages stay in the queue.
+// @Router /admin/dead-letters/{queue} [get]
+// @Security BearerAuth
+// @Produce json
+// @Param queue path string true "Queue name (e.g. topup)"
+// @Param limit query int false "Maximum messages to return (default 100, max 1000)"
+// @Success 200 {object} dto.DeadLetterListResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 503
+func (h *DeadLetterHandler) List(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a number"})
+			return
+		}
+		limit = n
+	}
+
+	res, err := h.usecase.List(c.Param("queue"), limit, currentEmail(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags Admin
+// @Summary Replay dead-lettered messages
+// @Description Republish the selected messages to their original exchange with a fresh retry budget
+// @Router /admin/dead-letters/{queue}/replay [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param queue path string true "Queue name (e.g. topup)"
+// @Param request body dto.ReplayRequest true "Message ids to replay"
+// @Success 200 {object} dto.DeadLetterActionResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 503
+func (h *DeadLetterHandler) Replay(c *gin.Context) {
+	var req dto.ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.Replay(c.Param("queue"), &req, currentEmail(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags Admin
+// @Summary Edit and replay a dead-lettered message
+// @Description Replace the message body and republish it. The transaction id cannot change, and the worker parks the message again if its account or amount differ from the transaction record. Sealed messages need DLQ_DECRYPT_EMAILS and are sealed again.
+// @Router /admin/dead-letters/{queue}/{messageId}/replay [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param queue path string true "Queue name (e.g. topup)"
+// @Param messageId path string true "Message id"
+// @Param request body dto.EditReplayRequest true "Corrected message"
+// @Success 200 {object} dto.DeadLetterActionResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 503
+func (h *DeadLetterHandler) EditAndReplay(c *gin.Context) {
+	var req dto.EditReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.EditAndReplay(c.Param("queue"), c.Param("messageId"), &req, currentEmail(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags Admin
+// @Summary Purge dead-lettered messages
+// @Description Drop the selected messages, or the whole queue when all is true
+// @Router /admin/dead-letters/{queue}/purge [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param queue path string true "Queue name (e.g. topup)"
+// @Param request body dto.PurgeRequest true "Messages to purge"
+// @Success 200 {object} dto.DeadLetterActionResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 503
+func (h *DeadLetterHandler) Purge(c *gin.Context) {
+	var req dto.PurgeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.Purge(c.Param("queue"), &req, currentEmail(c))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *DeadLetterHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrActorRequired), errors.Is(err, usecase.ErrNothingSelected),
+		errors.Is(err, usecase.ErrTransactionChanged), errors.Is(err, usecase.ErrInvalidMessage):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrSealed):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrUnknownQueue), errors.Is(err, usecase.ErrMessageNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrQueueUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessChecker is implemented by dependencies that can report readiness
+type ReadinessChecker interface {
+	IsReady() bool
+}
+
+type HealthHandler struct {
+	broker ReadinessChecker
+}
+
+func NewHealthHandler(broker ReadinessChecker) *HealthHandler {
+	return &HealthHandler{broker: broker}
+}
+
+// @Tags Health
+// @Summary Liveness probe
+// @Description Report that the process is up
+// @Router /health/live [get]
+// @Produce json
+// @Success 200 {object} map[string]string
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// @Tags Health
+// @Summary Readiness probe
+// @Description Report whether the message broker connection is up and its topology declared
+// @Router /health/ready [get]
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 503 {object} map[string]string
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if h.broker == nil || !h.broker.IsReady() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "broker": "disconnected"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "broker": "connected"})
+}
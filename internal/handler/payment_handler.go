@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/junicochandra/golang-api-service/internal/app/payment"
@@ -25,7 +28,10 @@ func NewPaymentHandler(uc payment.TopUpUseCase) *PaymentHandler {
 // @Param        request body dto.TopUpRequest true "TopUp request payload"
 // @Success      202 "transaction accepted"
 // @Failure      400 "bad request"
+// @Failure      422 "account does not accept top-ups"
+// @Failure      429 "top-up queue backlogged, see Retry-After"
 // @Failure      500 "internal server error"
+// @Failure      503 "top-up queue unavailable or no worker consuming it"
 func (h *PaymentHandler) CreateTopUp(c *gin.Context) {
 	var req dto.TopUpRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -35,9 +41,37 @@ func (h *PaymentHandler) CreateTopUp(c *gin.Context) {
 
 	txID, err := h.usecase.CreateTopUp(&req)
 	if err != nil {
+		if writeThrottled(c, err) {
+			return
+		}
+		if errors.Is(err, payment.ErrAccountNotAllowed) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, payment.ErrQueueUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusAccepted, gin.H{"transaction_id": txID, "status": "pending"})
 }
+
+// writeThrottled answers a top-up refused by backpressure with 429 or 503 and
+// a Retry-After header, and reports whether err was such a refusal
+func writeThrottled(c *gin.Context, err error) bool {
+	var throttled *payment.ThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+
+	status := http.StatusServiceUnavailable
+	if errors.Is(err, payment.ErrQueueBacklogged) {
+		status = http.StatusTooManyRequests
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+	return true
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	usecase "github.com/junicochandra/golang-api-service/internal/app/qris"
+	"github.com/junicochandra/golang-api-service/internal/app/qris/dto"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+)
+
+type QRISHandler struct {
+	usecase usecase.QRISUseCase
+}
+
+func NewQRISHandler(uc usecase.QRISUseCase) *QRISHandler {
+	return &QRISHandler{usecase: uc}
+}
+
+// @Tags QRIS
+// @Summary Generate a merchant QR payload
+// @Description Generate an EMVCo merchant-presented QR payload for one of the user's accounts. Static when amount is omitted, dynamic otherwise.
+// @Router /qris/generate [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.GenerateQRRequest true "Merchant data"
+// @Success 201 {object} dto.GenerateQRResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+func (h *QRISHandler) Generate(c *gin.Context) {
+	var req dto.GenerateQRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.Generate(&req, currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// @Tags QRIS
+// @Summary Parse a QR payload
+// @Description Verify the CRC and decode an EMVCo merchant-presented QR payload
+// @Router /qris/parse [post]
+// @Accept json
+// @Produce json
+// @Param request body dto.ParseQRRequest true "Scanned payload"
+// @Success 200 {object} dto.ParseQRResponse
+// @Failure 400
+func (h *QRISHandler) Parse(c *gin.Context) {
+	var req dto.ParseQRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.Parse(&req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags QRIS
+// @Summary Pay by QR
+// @Description Pay the merchant in a scanned QR payload from one of the user's accounts. A dynamic QR must match the one issued and can be paid only once.
+// @Router /qris/pay [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.PayQRRequest true "Scanned payload and payer account"
+// @Success 200 {object} dto.PayQRResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 409 "dynamic QR already paid"
+// @Failure 422
+// @Failure 500
+func (h *QRISHandler) Pay(c *gin.Context) {
+	var req dto.PayQRRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.Pay(&req, currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags QRIS
+// @Summary Render a QR payload as PNG
+// @Description Render a valid QR payload as a PNG image
+// @Router /qris/png [get]
+// @Produce png
+// @Param payload query string true "QR payload"
+// @Param size query int false "Image size in pixels (128-1024)" default(256)
+// @Success 200
+// @Failure 400
+// @Failure 500
+func (h *QRISHandler) RenderPNG(c *gin.Context) {
+	size, err := strconv.Atoi(c.DefaultQuery("size", "256"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid size"})
+		return
+	}
+
+	png, err := h.usecase.RenderPNG(c.Query("payload"), size)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+func (h *QRISHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidPayload),
+		errors.Is(err, usecase.ErrUnsupportedCurr),
+		errors.Is(err, usecase.ErrAmountRequired),
+		errors.Is(err, usecase.ErrAmountMismatch),
+		errors.Is(err, usecase.ErrSelfPayment),
+		errors.Is(err, usecase.ErrQRMismatch),
+		errors.Is(err, usecase.ErrInvalidImageSize):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrAccountNotFound), errors.Is(err, usecase.ErrUnknownMerchant), errors.Is(err, usecase.ErrUnknownQR):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrQRAlreadyPaid):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrInsufficientFunds), errors.Is(err, repository.ErrAccountUnavailable):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
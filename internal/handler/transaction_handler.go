@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	usecase "github.com/junicochandra/golang-api-service/internal/app/transaction"
+)
+
+const sseHeartbeat = 15 * time.Second
+
+type TransactionHandler struct {
+	usecase usecase.TransactionStatusUseCase
+}
+
+func NewTransactionHandler(uc usecase.TransactionStatusUseCase) *TransactionHandler {
+	return &TransactionHandler{usecase: uc}
+}
+
+// @Tags Transactions
+// @Summary Stream status changes of a transaction
+// @Description Server-Sent Events stream. Sends the current status first, then every change, and closes once the transaction is final.
+// @Router /transactions/{transactionId}/events [get]
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Param transactionId path string true "Transaction ID"
+// @Success 200 {object} dto.TransactionStatusEvent
+// @Failure 403
+// @Failure 404
+// @Failure 500
+func (h *TransactionHandler) StreamTransaction(c *gin.Context) {
+	sub, err := h.usecase.WatchTransaction(c.Param("transactionId"), currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	defer sub.Cancel()
+
+	c.SSEvent("status", sub.Current)
+	if sub.Current.IsFinal() {
+		return
+	}
+	h.stream(c, sub, true)
+}
+
+// @Tags Transactions
+// @Summary Stream status changes for all of the user's accounts
+// @Description Server-Sent Events stream of every transaction status change on accounts owned by the authenticated user
+// @Router /transactions/events [get]
+// @Security BearerAuth
+// @Produce text/event-stream
+// @Success 200 {object} dto.TransactionStatusEvent
+// @Failure 404
+// @Failure 500
+func (h *TransactionHandler) StreamAccounts(c *gin.Context) {
+	sub, err := h.usecase.WatchAccounts(currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+	defer sub.Cancel()
+
+	h.stream(c, sub, false)
+}
+
+func (h *TransactionHandler) stream(c *gin.Context, sub *usecase.Subscription, stopOnFinal bool) {
+	// Disable proxy buffering so events reach the browser immediately
+	c.Header("X-Accel-Buffering", "no")
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case evt, ok := <-sub.Events:
+			if !ok {
+				return false
+			}
+			c.SSEvent("status", evt)
+			return !(stopOnFinal && evt.IsFinal())
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
+
+func (h *TransactionHandler) writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound), errors.Is(err, usecase.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// currentEmail returns the email claimed by the JWT middleware
+func currentEmail(c *gin.Context) string {
+	email, _ := c.Get("email")
+	s, _ := email.(string)
+	return s
+}
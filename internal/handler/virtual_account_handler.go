@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/junicochandra/golang-api-service/internal/app/payment"
+	usecase "github.com/junicochandra/golang-api-service/internal/app/virtualaccount"
+	"github.com/junicochandra/golang-api-service/internal/app/virtualaccount/dto"
+)
+
+type VirtualAccountHandler struct {
+	usecase usecase.VirtualAccountUseCase
+}
+
+func NewVirtualAccountHandler(uc usecase.VirtualAccountUseCase) *VirtualAccountHandler {
+	return &VirtualAccountHandler{usecase: uc}
+}
+
+// @Tags Virtual Accounts
+// @Summary Issue a virtual account
+// @Description Issue (or reactivate) the user's virtual account number for a bank, mapped to one of the user's wallet accounts
+// @Router /virtual-accounts [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.IssueVARequest true "Wallet account and bank"
+// @Success 201 {object} dto.VAResponse
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 500
+func (h *VirtualAccountHandler) Issue(c *gin.Context) {
+	var req dto.IssueVARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.Issue(&req, currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, res)
+}
+
+// @Tags Virtual Accounts
+// @Summary List virtual accounts
+// @Description List the virtual accounts of the authenticated user
+// @Router /virtual-accounts [get]
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} dto.VAResponse
+// @Failure 403
+// @Failure 500
+func (h *VirtualAccountHandler) List(c *gin.Context) {
+	res, err := h.usecase.List(currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags Virtual Accounts
+// @Summary Look up a virtual account
+// @Description Get the wallet mapping of a virtual account number
+// @Router /virtual-accounts/{vaNumber} [get]
+// @Security BearerAuth
+// @Produce json
+// @Param vaNumber path string true "Virtual account number"
+// @Success 200 {object} dto.VAResponse
+// @Failure 403
+// @Failure 404
+// @Failure 500
+func (h *VirtualAccountHandler) Lookup(c *gin.Context) {
+	res, err := h.usecase.Lookup(c.Param("vaNumber"), currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags Virtual Accounts
+// @Summary Deactivate a virtual account
+// @Description Deactivate a virtual account. Incoming credits to it are refused until it is issued again.
+// @Router /virtual-accounts/{vaNumber} [delete]
+// @Security BearerAuth
+// @Produce json
+// @Param vaNumber path string true "Virtual account number"
+// @Success 200 {object} dto.VAResponse
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Failure 500
+func (h *VirtualAccountHandler) Deactivate(c *gin.Context) {
+	res, err := h.usecase.Deactivate(c.Param("vaNumber"), currentEmail(c))
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// @Tags Virtual Accounts
+// @Summary Bank credit notification
+// @Description Callback for banks to notify an incoming transfer to a virtual account. Idempotent on bankReference.
+// @Router /virtual-accounts/callbacks/credit [post]
+// @Accept json
+// @Produce json
+// @Param X-Callback-Token header string true "Shared callback secret"
+// @Param request body dto.CreditNotification true "Credit notification"
+// @Success 202 {object} dto.CreditResponse
+// @Failure 400
+// @Failure 401
+// @Failure 404
+// @Failure 409
+// @Failure 429 "top-up queue backlogged, see Retry-After"
+// @Failure 500
+// @Failure 503 "no worker consuming top-ups, see Retry-After"
+func (h *VirtualAccountHandler) CreditCallback(c *gin.Context) {
+	var req dto.CreditNotification
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.HandleCredit(&req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+// @Tags Virtual Accounts
+// @Summary Simulate a bank credit
+// @Description Local testing only: simulate a bank transfer into a virtual account. Enabled with VA_SIMULATOR=true. Admins only.
+// @Router /virtual-accounts/simulate/credit [post]
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body dto.SimulateCreditRequest true "Simulated transfer"
+// @Success 202 {object} dto.CreditResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 429 "top-up queue backlogged, see Retry-After"
+// @Failure 500
+// @Failure 503 "no worker consuming top-ups, see Retry-After"
+func (h *VirtualAccountHandler) SimulateCredit(c *gin.Context) {
+	var req dto.SimulateCreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := h.usecase.SimulateCredit(&req)
+	if err != nil {
+		h.writeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, res)
+}
+
+func (h *VirtualAccountHandler) writeError(c *gin.Context, err error) {
+	if writeThrottled(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, usecase.ErrUnknownBank), errors.Is(err, usecase.ErrInvalidVANumber):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrNotFound), errors.Is(err, usecase.ErrAccountNotFound), errors.Is(err, usecase.ErrSimulatorDisabled):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, usecase.ErrInactive), errors.Is(err, usecase.ErrAlreadyInactive), errors.Is(err, usecase.ErrCreditInProgress), errors.Is(err, usecase.ErrCreditMismatch):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, payment.ErrAccountNotAllowed):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
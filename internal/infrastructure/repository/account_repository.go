@@ -2,11 +2,15 @@ package repository
 
 import (
 	"errors"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/junicochandra/golang-api-service/internal/domain/entity"
 	accountRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
 	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type accountRepository struct {
@@ -17,6 +21,22 @@ func NewAccountRepository(db *gorm.DB) accountRepo.AccountRepository {
 	return &accountRepository{db: database.DB}
 }
 
+func (repo *accountRepository) GetAll() ([]entity.Account, error) {
+	var accounts []entity.Account
+	if err := repo.db.Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (repo *accountRepository) GetByUserID(userID uint64) ([]entity.Account, error) {
+	var accounts []entity.Account
+	if err := repo.db.Where("user_id = ?", userID).Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
 func (repo *accountRepository) GetByAccountNumber(accountNumber string) (*entity.Account, error) {
 	var account entity.Account
 	if err := repo.db.Where("account_number = ?", accountNumber).First(&account).Error; err != nil {
@@ -28,6 +48,147 @@ func (repo *accountRepository) GetByAccountNumber(accountNumber string) (*entity
 	return &account, nil
 }
 
-func (repo *accountRepository) UpdateBalanceTx(account *entity.Account) error {
-	return repo.db.Save(account).Error
+// CreditTx records the message in the inbox, adds amount to the locked
+// account and marks the transaction completed, all in one transaction. It
+// returns ErrAlreadyProcessed when the inbox already holds the message and
+// ErrAccountUnavailable when the account is missing or refuses credits.
+func (repo *accountRepository) CreditTx(accountNumber string, amount decimal.Decimal, transactionID string, inbox *entity.ProcessedMessage) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := recordProcessed(tx, inbox); err != nil {
+			return err
+		}
+
+		var account entity.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_number = ?", accountNumber).
+			First(&account).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return accountRepo.ErrAccountUnavailable
+			}
+			return err
+		}
+		if !account.CanCredit() {
+			return accountRepo.ErrAccountUnavailable
+		}
+
+		now := time.Now()
+		// Only touch the balance so a concurrent status change is not overwritten
+		if err := tx.Model(&account).Updates(map[string]interface{}{
+			"balance":    account.Balance.Add(amount),
+			"updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&entity.Transaction{}).
+			Where("transaction_id = ?", transactionID).
+			Updates(map[string]interface{}{"status": "completed", "settled_at": now, "updated_at": now}).Error
+	})
+}
+
+// UpdateStatusTx persists the account status and its audit log entry in one
+// transaction. The account row is locked and must still be in log.FromStatus,
+// else ErrStatusChanged; closing also requires a zero balance, else
+// ErrBalanceNotZero. account.Balance is refreshed from the locked row.
+func (repo *accountRepository) UpdateStatusTx(account *entity.Account, log *entity.AccountStatusLog) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		var locked entity.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_number = ?", account.AccountNumber).
+			First(&locked).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return accountRepo.ErrAccountUnavailable
+			}
+			return err
+		}
+		current := locked.Status
+		if current == "" {
+			current = entity.AccountStatusActive
+		}
+		if current != log.FromStatus {
+			return accountRepo.ErrStatusChanged
+		}
+		if account.Status == entity.AccountStatusClosed && !locked.Balance.IsZero() {
+			return accountRepo.ErrBalanceNotZero
+		}
+		account.Balance = locked.Balance
+
+		if err := tx.Model(&locked).
+			Updates(map[string]interface{}{
+				"status":            account.Status,
+				"status_reason":     account.StatusReason,
+				"status_changed_at": account.StatusChangedAt,
+				"status_changed_by": account.StatusChangedBy,
+				"updated_at":        account.UpdatedAt,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(log).Error
+	})
+}
+
+// TransferTx moves txn.Amount from the sender to the receiver account and
+// records txn, all in one transaction with both account rows locked. A
+// non-nil qr marks a single-use QR as paid in the same transaction; if it
+// already is, nothing moves and ErrQRAlreadyPaid is returned.
+func (repo *accountRepository) TransferTx(txn *entity.Transaction, qr *entity.QRISPayment) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if qr != nil {
+			// A concurrent payment of the same QR blocks here until the first
+			// one ends, then fails on the primary key
+			qr.TransactionID = txn.TransactionID
+			err := tx.Create(qr).Error
+			var myErr *mysql.MySQLError
+			if errors.As(err, &myErr) && myErr.Number == mysqlDuplicateEntry {
+				return accountRepo.ErrQRAlreadyPaid
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// Lock in account number order so concurrent transfers cannot deadlock
+		var accounts []entity.Account
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("account_number IN ?", []string{txn.SenderAccountID, txn.ReceiverAccountID}).
+			Order("account_number").
+			Find(&accounts).Error; err != nil {
+			return err
+		}
+
+		var sender, receiver *entity.Account
+		for i := range accounts {
+			switch accounts[i].AccountNumber {
+			case txn.SenderAccountID:
+				sender = &accounts[i]
+			case txn.ReceiverAccountID:
+				receiver = &accounts[i]
+			}
+		}
+		if sender == nil || receiver == nil || sender == receiver {
+			return accountRepo.ErrAccountUnavailable
+		}
+		if !sender.CanDebit() || !receiver.CanCredit() {
+			return accountRepo.ErrAccountUnavailable
+		}
+		if sender.Balance.LessThan(txn.Amount) {
+			return accountRepo.ErrInsufficientFunds
+		}
+
+		now := time.Now()
+		sender.Balance = sender.Balance.Sub(txn.Amount)
+		sender.UpdatedAt = now
+		receiver.Balance = receiver.Balance.Add(txn.Amount)
+		receiver.UpdatedAt = now
+		if err := tx.Model(sender).Select("balance", "updated_at").Updates(sender).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(receiver).Select("balance", "updated_at").Updates(receiver).Error; err != nil {
+			return err
+		}
+
+		txn.SettledAt = &now
+		txn.UpdatedAt = now
+		return tx.Create(txn).Error
+	})
 }
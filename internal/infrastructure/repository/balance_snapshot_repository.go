@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	snapshotRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type balanceSnapshotRepository struct {
+	db *gorm.DB
+}
+
+func NewBalanceSnapshotRepository(db *gorm.DB) snapshotRepo.BalanceSnapshotRepository {
+	return &balanceSnapshotRepository{db: database.DB}
+}
+
+// Upsert stores the snapshot, replacing an earlier run for the same account and day
+func (repo *balanceSnapshotRepository) Upsert(snapshot *entity.BalanceSnapshot) error {
+	return repo.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_number"}, {Name: "snapshot_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"as_of", "balance", "currency"}),
+	}).Create(snapshot).Error
+}
+
+func (repo *balanceSnapshotRepository) GetLatestAtOrBefore(accountNumber string, at time.Time) (*entity.BalanceSnapshot, error) {
+	var snapshot entity.BalanceSnapshot
+	if err := repo.db.Where("account_number = ? AND as_of <= ?", accountNumber, at).
+		Order("as_of DESC").
+		First(&snapshot).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
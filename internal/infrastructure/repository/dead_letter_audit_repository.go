@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	auditRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
+	"gorm.io/gorm"
+)
+
+type deadLetterAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewDeadLetterAuditRepository(db *gorm.DB) auditRepo.DeadLetterAuditRepository {
+	return &deadLetterAuditRepository{db: database.DB}
+}
+
+func (repo *deadLetterAuditRepository) Create(log *entity.DeadLetterAuditLog) error {
+	return repo.db.Create(log).Error
+}
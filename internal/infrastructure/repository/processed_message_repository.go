@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	inboxRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
+	"gorm.io/gorm"
+)
+
+// mysqlDuplicateEntry is ER_DUP_ENTRY
+const mysqlDuplicateEntry = 1062
+
+type processedMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewProcessedMessageRepository(db *gorm.DB) inboxRepo.ProcessedMessageRepository {
+	return &processedMessageRepository{db: database.DB}
+}
+
+// DeleteBefore prunes inbox rows older than t; redeliveries arrive within
+// minutes, so old rows only cost space
+func (repo *processedMessageRepository) DeleteBefore(t time.Time) (int64, error) {
+	res := repo.db.Where("processed_at < ?", t).Delete(&entity.ProcessedMessage{})
+	return res.RowsAffected, res.Error
+}
+
+// recordProcessed inserts the inbox row inside tx. A concurrent duplicate
+// blocks on the primary key until the first transaction ends, then fails.
+func recordProcessed(tx *gorm.DB, msg *entity.ProcessedMessage) error {
+	err := tx.Create(msg).Error
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) && myErr.Number == mysqlDuplicateEntry {
+		return inboxRepo.ErrAlreadyProcessed
+	}
+	return err
+}
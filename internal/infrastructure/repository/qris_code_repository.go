@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	qrisRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
+	"gorm.io/gorm"
+)
+
+type qrisCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewQRISCodeRepository(db *gorm.DB) qrisRepo.QRISCodeRepository {
+	return &qrisCodeRepository{db: database.DB}
+}
+
+func (repo *qrisCodeRepository) Create(code *entity.QRISCode) error {
+	return repo.db.Create(code).Error
+}
+
+func (repo *qrisCodeRepository) GetByReference(referenceLabel string) (*entity.QRISCode, error) {
+	var code entity.QRISCode
+	if err := repo.db.Where("reference_label = ?", referenceLabel).First(&code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &code, nil
+}
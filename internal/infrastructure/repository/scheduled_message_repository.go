@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	scheduledRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
+	"gorm.io/gorm"
+)
+
+type scheduledMessageRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledMessageRepository(db *gorm.DB) scheduledRepo.ScheduledMessageRepository {
+	return &scheduledMessageRepository{db: database.DB}
+}
+
+func (repo *scheduledMessageRepository) Create(msg *entity.ScheduledMessage) error {
+	return repo.db.Create(msg).Error
+}
+
+func (repo *scheduledMessageRepository) GetByMessageID(messageID string) (*entity.ScheduledMessage, error) {
+	var msg entity.ScheduledMessage
+	if err := repo.db.Where("message_id = ?", messageID).First(&msg).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (repo *scheduledMessageRepository) CompareAndSetStatus(messageID, from, to string) (bool, error) {
+	res := repo.db.Model(&entity.ScheduledMessage{}).
+		Where("message_id = ? AND status = ?", messageID, from).
+		Update("status", to)
+	return res.RowsAffected > 0, res.Error
+}
+
+// DeleteSettledBefore prunes messages that were released, cancelled or failed
+// before t; pending rows stay until their message is released
+func (repo *scheduledMessageRepository) DeleteSettledBefore(t time.Time) (int64, error) {
+	res := repo.db.
+		Where("status <> ? AND updated_at < ?", entity.ScheduledStatusPending, t).
+		Delete(&entity.ScheduledMessage{})
+	return res.RowsAffected, res.Error
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/junicochandra/golang-api-service/internal/domain/entity"
 	transactionRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
@@ -32,6 +33,33 @@ func (repo *transactionRepository) GetByTransactionID(transactionId string) (*en
 	return &txn, nil
 }
 
+// GetLatestByReference returns the newest transaction carrying reference
+func (repo *transactionRepository) GetLatestByReference(reference string) (*entity.Transaction, error) {
+	var txn entity.Transaction
+	if err := repo.db.Where("reference = ?", reference).Order("id DESC").First(&txn).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &txn, nil
+}
+
 func (repo *transactionRepository) UpdateStatus(transactionId string, status string) error {
 	return repo.db.Model(&entity.Transaction{}).Where("transaction_id = ?", transactionId).Update("status", status).Error
 }
+
+// ListSettledByAccountBetween returns transactions touching the account that
+// settled in (from, to]. settled_at is written once with the balance change,
+// so later status updates cannot move a transaction into another window.
+func (repo *transactionRepository) ListSettledByAccountBetween(accountNumber string, from, to time.Time) ([]entity.Transaction, error) {
+	var txns []entity.Transaction
+	if err := repo.db.
+		Where("(sender_account_id = ? OR receiver_account_id = ?)", accountNumber, accountNumber).
+		Where("settled_at > ? AND settled_at <= ?", from, to).
+		Order("settled_at ASC").
+		Find(&txns).Error; err != nil {
+		return nil, err
+	}
+	return txns, nil
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	vaRepo "github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
+	"gorm.io/gorm"
+)
+
+type virtualAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewVirtualAccountRepository(db *gorm.DB) vaRepo.VirtualAccountRepository {
+	return &virtualAccountRepository{db: database.DB}
+}
+
+func (repo *virtualAccountRepository) Create(va *entity.VirtualAccount) error {
+	return repo.db.Create(va).Error
+}
+
+func (repo *virtualAccountRepository) Update(va *entity.VirtualAccount) error {
+	return repo.db.Save(va).Error
+}
+
+func (repo *virtualAccountRepository) GetByNumber(vaNumber string) (*entity.VirtualAccount, error) {
+	var va entity.VirtualAccount
+	if err := repo.db.Where("va_number = ?", vaNumber).First(&va).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &va, nil
+}
+
+func (repo *virtualAccountRepository) GetByUserAndBank(userID uint64, bankCode string) (*entity.VirtualAccount, error) {
+	var va entity.VirtualAccount
+	if err := repo.db.Where("user_id = ? AND bank_code = ?", userID, bankCode).First(&va).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &va, nil
+}
+
+func (repo *virtualAccountRepository) GetByUserID(userID uint64) ([]entity.VirtualAccount, error) {
+	var vas []entity.VirtualAccount
+	if err := repo.db.Where("user_id = ?", userID).Find(&vas).Error; err != nil {
+		return nil, err
+	}
+	return vas, nil
+}
+
+func (repo *virtualAccountRepository) CreateCredit(credit *entity.VirtualAccountCredit) error {
+	return repo.db.Create(credit).Error
+}
+
+func (repo *virtualAccountRepository) UpdateCredit(credit *entity.VirtualAccountCredit) error {
+	return repo.db.Save(credit).Error
+}
+
+func (repo *virtualAccountRepository) GetCreditByReference(bankReference string) (*entity.VirtualAccountCredit, error) {
+	var credit entity.VirtualAccountCredit
+	if err := repo.db.Where("bank_reference = ?", bankReference).First(&credit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &credit, nil
+}
+
+func (repo *virtualAccountRepository) ClaimCredit(credit *entity.VirtualAccountCredit, status string, updatedAt time.Time) (bool, error) {
+	res := repo.db.Model(&entity.VirtualAccountCredit{}).
+		Where("id = ? AND status = ? AND updated_at = ?", credit.ID, status, updatedAt).
+		Updates(map[string]interface{}{"status": credit.Status, "updated_at": credit.UpdatedAt})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,79 @@
+package membroker
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+// PublishDelayed implements messaging.DelayedPublisher with a timer per
+// message; the message is published when the timer fires
+func (b *Broker) PublishDelayed(ctx context.Context, exchange, routingKey string, msg messaging.Message, delay time.Duration) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return "", ErrClosed
+	}
+	if b.delayed == nil {
+		b.delayed = map[string]*time.Timer{}
+	}
+	if t, ok := b.delayed[msg.ID]; ok {
+		t.Stop()
+	}
+
+	id := msg.ID
+	var timer *time.Timer
+	timer = time.AfterFunc(delay, func() {
+		b.mu.Lock()
+		current := b.delayed[id] == timer
+		if current {
+			delete(b.delayed, id)
+		}
+		b.mu.Unlock()
+
+		if current {
+			_ = b.Publish(context.Background(), exchange, routingKey, msg)
+		}
+	})
+	b.delayed[id] = timer
+	return id, nil
+}
+
+func (b *Broker) CancelDelayed(ctx context.Context, messageID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.delayed[messageID]
+	if !ok {
+		return messaging.ErrNotScheduled
+	}
+	t.Stop()
+	delete(b.delayed, messageID)
+	return nil
+}
+
+// Pending returns the IDs of delayed messages that have not been published yet
+func (b *Broker) Pending() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ids := make([]string, 0, len(b.delayed))
+	for id := range b.delayed {
+		ids = append(ids, id)
+	}
+	return ids
+}
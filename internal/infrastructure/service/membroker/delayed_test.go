@@ -0,0 +1,119 @@
+package membroker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+func TestPublishDelayed(t *testing.T) {
+	b := New()
+	t.Cleanup(func() { _ = b.Close() })
+	b.Bind("delay.exchange", "due", "due_queue")
+
+	id, err := b.PublishDelayed(context.Background(), "delay.exchange", "due", messaging.Message{Body: []byte("later")}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == "" {
+		t.Fatal("no message id assigned")
+	}
+	if got := b.Messages("due_queue"); len(got) != 0 {
+		t.Fatalf("published before the delay: %d messages", len(got))
+	}
+	if pending := b.Pending(); len(pending) != 1 || pending[0] != id {
+		t.Fatalf("pending = %v, want [%s]", pending, id)
+	}
+
+	waitFor(t, func() bool { return len(b.Messages("due_queue")) == 1 })
+	msg := b.Messages("due_queue")[0]
+	if msg.ID != id || string(msg.Body) != "later" {
+		t.Fatalf("published %q with id %s, want %q with id %s", msg.Body, msg.ID, "later", id)
+	}
+	if pending := b.Pending(); len(pending) != 0 {
+		t.Fatalf("still pending after publish: %v", pending)
+	}
+	if err := b.CancelDelayed(context.Background(), id); !errors.Is(err, messaging.ErrNotScheduled) {
+		t.Fatalf("cancel after publish: got %v, want ErrNotScheduled", err)
+	}
+}
+
+func TestCancelDelayed(t *testing.T) {
+	b := New()
+	t.Cleanup(func() { _ = b.Close() })
+	b.Bind("delay.exchange", "due", "due_queue")
+
+	id, err := b.PublishDelayed(context.Background(), "delay.exchange", "due", messaging.Message{ID: "reminder-1", Body: []byte("x")}, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "reminder-1" {
+		t.Fatalf("id = %s, want the caller's reminder-1", id)
+	}
+	if err := b.CancelDelayed(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.CancelDelayed(context.Background(), id); !errors.Is(err, messaging.ErrNotScheduled) {
+		t.Fatalf("second cancel: got %v, want ErrNotScheduled", err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	if got := b.Messages("due_queue"); len(got) != 0 {
+		t.Fatalf("cancelled message was published: %d messages", len(got))
+	}
+}
+
+func TestPublishDelayedReplacesSameID(t *testing.T) {
+	b := New()
+	t.Cleanup(func() { _ = b.Close() })
+	b.Bind("delay.exchange", "due", "due_queue")
+
+	ctx := context.Background()
+	if _, err := b.PublishDelayed(ctx, "delay.exchange", "due", messaging.Message{ID: "m", Body: []byte("first")}, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PublishDelayed(ctx, "delay.exchange", "due", messaging.Message{ID: "m", Body: []byte("second")}, 60*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	waitFor(t, func() bool { return len(b.Messages("due_queue")) > 0 })
+	time.Sleep(100 * time.Millisecond)
+	got := b.Messages("due_queue")
+	if len(got) != 1 || string(got[0].Body) != "second" {
+		t.Fatalf("got %d messages, want only the rescheduled one", len(got))
+	}
+}
+
+func TestCloseStopsDelayed(t *testing.T) {
+	b := New()
+	b.Bind("delay.exchange", "due", "due_queue")
+
+	if _, err := b.PublishDelayed(context.Background(), "delay.exchange", "due", messaging.Message{Body: []byte("x")}, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PublishDelayed(context.Background(), "delay.exchange", "due", messaging.Message{}, time.Millisecond); !errors.Is(err, ErrClosed) {
+		t.Fatalf("publish after close: got %v, want ErrClosed", err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if got := b.Messages("due_queue"); len(got) != 0 {
+		t.Fatalf("published after close: %d messages", len(got))
+	}
+}
+
+// waitFor polls cond for up to a second
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 1s")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
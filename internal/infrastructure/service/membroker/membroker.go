@@ -0,0 +1,411 @@
+// Package membroker is an in-process messaging.BrokerPort. It keeps queues in
+// memory and follows the RabbitMQ adapter's semantics closely enough to run the
+// payment flow and the worker in unit tests or a local sandbox.
+package membroker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+const defaultMaxAttempts = 5
+
+var (
+	ErrClosed          = errors.New("membroker: broker closed")
+	errUnknownDelivery = errors.New("membroker: delivery already settled or not from this broker")
+)
+
+type Options struct {
+	// MaxAttempts is how many retries a message gets; the next requeueing
+	// nack dead-letters it (default 5, negative for unlimited)
+	MaxAttempts int
+}
+
+// Broker routes by exact routing key, or any key for bindings made with "#".
+// The default exchange "" routes to the queue named by the routing key.
+type Broker struct {
+	opts Options
+
+	mu       sync.Mutex
+	bindings map[string][]binding // exchange -> bindings
+	queues   map[string]*queue
+	nextTag  uint64
+	closed   bool
+	done     chan struct{}
+	// delayed holds the timers of PublishDelayed messages by ID
+	delayed map[string]*time.Timer
+}
+
+type binding struct {
+	key   string
+	queue string
+}
+
+type queue struct {
+	ready       []*entry
+	unacked     map[uint64]*entry
+	deadLetters []messaging.Message
+	consumers   int
+	changed     chan struct{} // closed and replaced on every change
+}
+
+type entry struct {
+	tag         uint64
+	msg         messaging.Message
+	routingKey  string
+	redelivered bool
+	attempts    int
+	sub         *subscription
+}
+
+func New() *Broker {
+	return NewWithOptions(Options{})
+}
+
+func NewWithOptions(opts Options) *Broker {
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = defaultMaxAttempts
+	}
+	return &Broker{
+		opts:     opts,
+		bindings: map[string][]binding{},
+		queues:   map[string]*queue{},
+		done:     make(chan struct{}),
+	}
+}
+
+// Bind routes messages published to exchange with key into queue
+func (b *Broker) Bind(exchange, key, queueName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.queue(queueName)
+	b.bindings[exchange] = append(b.bindings[exchange], binding{key: key, queue: queueName})
+}
+
+// Messages returns the messages waiting in queue
+func (b *Broker) Messages(queueName string) []messaging.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q := b.queue(queueName)
+	out := make([]messaging.Message, 0, len(q.ready))
+	for _, e := range q.ready {
+		out = append(out, e.msg)
+	}
+	return out
+}
+
+// DeadLetters returns the messages rejected or out of attempts on queue
+func (b *Broker) DeadLetters(queueName string) []messaging.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]messaging.Message(nil), b.queue(queueName).deadLetters...)
+}
+
+func (b *Broker) Publish(ctx context.Context, exchange, routingKey string, msg messaging.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrClosed
+	}
+
+	var targets []string
+	if exchange == "" {
+		if _, ok := b.queues[routingKey]; ok {
+			targets = append(targets, routingKey)
+		}
+	}
+	for _, bind := range b.bindings[exchange] {
+		if bind.key == "#" || bind.key == routingKey {
+			targets = append(targets, bind.queue)
+		}
+	}
+	if len(targets) == 0 {
+		if msg.Mandatory {
+			return fmt.Errorf("%w: exchange=%s routingKey=%s", messaging.ErrUnroutable, exchange, routingKey)
+		}
+		return nil
+	}
+
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+	msg.Body = append([]byte(nil), msg.Body...)
+
+	for _, name := range targets {
+		q := b.queue(name)
+		b.nextTag++
+		q.ready = append(q.ready, &entry{tag: b.nextTag, msg: msg, routingKey: routingKey})
+		q.notify()
+	}
+	return nil
+}
+
+func (b *Broker) Subscribe(ctx context.Context, queueName string, opts messaging.SubscribeOptions) (messaging.Subscription, error) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	q := b.queue(queueName)
+	q.consumers++
+	b.mu.Unlock()
+
+	prefetch := opts.Prefetch
+	if prefetch <= 0 {
+		prefetch = 1
+	}
+
+	sub := &subscription{
+		broker:   b,
+		name:     queueName,
+		q:        q,
+		prefetch: prefetch,
+		out:      make(chan messaging.Delivery),
+		done:     make(chan struct{}),
+	}
+	go sub.run(ctx)
+	return sub, nil
+}
+
+// QueueStats counts the ready messages and open subscriptions of a queue
+func (b *Broker) QueueStats(ctx context.Context, queueName string) (messaging.QueueStats, error) {
+	if err := ctx.Err(); err != nil {
+		return messaging.QueueStats{}, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q := b.queue(queueName)
+	return messaging.QueueStats{Messages: len(q.ready), Consumers: q.consumers}, nil
+}
+
+func (b *Broker) Ack(d messaging.Delivery) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, q, err := b.settle(d)
+	if err != nil {
+		return err
+	}
+	e.sub.inflight--
+	q.notify()
+	return nil
+}
+
+// Nack with requeue puts the message back at the head of the queue until its
+// attempts are used up; without requeue it is dead-lettered.
+func (b *Broker) Nack(d messaging.Delivery, requeue bool) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	e, q, err := b.settle(d)
+	if err != nil {
+		return err
+	}
+	e.sub.inflight--
+	e.sub = nil
+
+	if !requeue || (b.opts.MaxAttempts > 0 && e.attempts >= b.opts.MaxAttempts) {
+		q.deadLetters = append(q.deadLetters, e.msg)
+	} else {
+		e.attempts++
+		e.redelivered = true
+		q.ready = append([]*entry{e}, q.ready...)
+	}
+	q.notify()
+	return nil
+}
+
+func (b *Broker) WaitReady(ctx context.Context) error {
+	if b.IsReady() {
+		return nil
+	}
+	return ErrClosed
+}
+
+func (b *Broker) IsReady() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.closed
+}
+
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		close(b.done)
+		for id, t := range b.delayed {
+			t.Stop()
+			delete(b.delayed, id)
+		}
+	}
+	return nil
+}
+
+// settle removes an unacked delivery; callers hold b.mu
+func (b *Broker) settle(d messaging.Delivery) (*entry, *queue, error) {
+	h, ok := d.Handle.(handle)
+	if !ok || h.broker != b {
+		return nil, nil, errUnknownDelivery
+	}
+	e, ok := h.q.unacked[h.tag]
+	if !ok {
+		return nil, nil, errUnknownDelivery
+	}
+	delete(h.q.unacked, h.tag)
+	return e, h.q, nil
+}
+
+// queue returns the named queue, creating it on first use; callers hold b.mu
+func (b *Broker) queue(name string) *queue {
+	q, ok := b.queues[name]
+	if !ok {
+		q = &queue{unacked: map[uint64]*entry{}, changed: make(chan struct{})}
+		b.queues[name] = q
+	}
+	return q
+}
+
+func (q *queue) notify() {
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+type handle struct {
+	broker *Broker
+	q      *queue
+	tag    uint64
+}
+
+type subscription struct {
+	broker   *Broker
+	name     string
+	q        *queue
+	prefetch int
+	inflight int // guarded by broker.mu
+
+	out       chan messaging.Delivery
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *subscription) Deliveries() <-chan messaging.Delivery {
+	return s.out
+}
+
+// Close stops the subscription and requeues whatever it still holds unacked
+func (s *subscription) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		b := s.broker
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		var held []*entry
+		for tag, e := range s.q.unacked {
+			if e.sub == s {
+				delete(s.q.unacked, tag)
+				e.sub = nil
+				e.redelivered = true
+				held = append(held, e)
+			}
+		}
+		if len(held) > 0 {
+			s.q.ready = append(held, s.q.ready...)
+			s.q.notify()
+		}
+	})
+	return nil
+}
+
+func (s *subscription) run(ctx context.Context) {
+	defer close(s.out)
+
+	b := s.broker
+	defer func() {
+		b.mu.Lock()
+		s.q.consumers--
+		b.mu.Unlock()
+	}()
+	for {
+		b.mu.Lock()
+		var e *entry
+		if s.inflight < s.prefetch && len(s.q.ready) > 0 {
+			e = s.q.ready[0]
+			s.q.ready = s.q.ready[1:]
+			e.sub = s
+			s.inflight++
+			s.q.unacked[e.tag] = e
+		}
+		changed := s.q.changed
+		b.mu.Unlock()
+
+		if e == nil {
+			select {
+			case <-changed:
+				continue
+			case <-ctx.Done():
+			case <-s.done:
+			case <-b.done:
+			}
+			return
+		}
+
+		d := messaging.Delivery{
+			Message:     e.msg,
+			Queue:       s.name,
+			RoutingKey:  e.routingKey,
+			Redelivered: e.redelivered,
+			Attempts:    e.attempts,
+			Handle:      handle{broker: b, q: s.q, tag: e.tag},
+		}
+		select {
+		case s.out <- d:
+		case <-ctx.Done():
+			s.requeue(e)
+			return
+		case <-s.done:
+			s.requeue(e)
+			return
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// requeue puts back a message that was taken but never handed out
+func (s *subscription) requeue(e *entry) {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := s.q.unacked[e.tag]; !ok {
+		return
+	}
+	delete(s.q.unacked, e.tag)
+	s.inflight--
+	e.sub = nil
+	s.q.ready = append([]*entry{e}, s.q.ready...)
+	s.q.notify()
+}
@@ -0,0 +1,24 @@
+package membroker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	b := NewWithOptions(Options{MaxAttempts: 2})
+	t.Cleanup(func() { _ = b.Close() })
+	b.Bind("check.exchange", "check.created", "check_queue")
+
+	conformance.Test(t, conformance.Fixture{
+		Broker:            b,
+		Exchange:          "check.exchange",
+		RoutingKey:        "check.created",
+		UnboundRoutingKey: "check.unbound",
+		Queue:             "check_queue",
+		MaxAttempts:       2,
+		Redelivery:        500 * time.Millisecond,
+	})
+}
@@ -0,0 +1,202 @@
+package natsbroker
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// maxDeadLetterScan bounds how many parked messages one admin call reads
+const maxDeadLetterScan = 1000
+
+// DeadLetterAdmin implements messaging.DeadLetterPort on the parking streams.
+// Parked messages are addressed by stream sequence, so unselected ones never
+// move.
+type DeadLetterAdmin struct {
+	b *Broker
+}
+
+func NewDeadLetterAdmin(b *Broker) *DeadLetterAdmin {
+	return &DeadLetterAdmin{b: b}
+}
+
+type parked struct {
+	seq    uint64
+	letter messaging.DeadLetter
+}
+
+func (a *DeadLetterAdmin) Peek(queue string, limit int) ([]messaging.DeadLetter, error) {
+	msgs, err := a.scan(queue, limit)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]messaging.DeadLetter, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, m.letter)
+	}
+	return out, nil
+}
+
+func (a *DeadLetterAdmin) Replay(queue string, ids []string, edits map[string][]byte) ([]string, error) {
+	msgs, err := a.scan(queue, maxDeadLetterScan)
+	if err != nil {
+		return nil, err
+	}
+	stream, err := a.stream(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := toSet(ids)
+	var replayed []string
+	for _, p := range msgs {
+		if !selected[p.letter.MessageID] {
+			continue
+		}
+
+		m := nats.NewMsg(p.letter.RoutingKey)
+		m.Data = p.letter.Body
+		if edited, ok := edits[p.letter.MessageID]; ok {
+			m.Data = edited
+		}
+		for k, v := range p.letter.Headers {
+			if s, ok := v.(string); ok {
+				m.Header.Set(k, s)
+			}
+		}
+		// Start over with a clean retry budget
+		m.Header.Del(headerOrigSubject)
+		m.Header.Del(headerDeathReason)
+		m.Header.Del(headerRetryCount)
+		m.Header.Del(headerDeadLetterAt)
+		m.Header.Set(headerMessageID, p.letter.MessageID)
+		m.Header.Set("X-Replayed-At", time.Now().UTC().Format(time.RFC3339))
+
+		ctx, cancel := context.WithTimeout(context.Background(), a.b.opts.PublishTimeout)
+		_, err := a.b.js.PublishMsg(ctx, m)
+		if err == nil {
+			err = stream.DeleteMsg(ctx, p.seq)
+		}
+		cancel()
+		if err != nil {
+			return replayed, err
+		}
+		replayed = append(replayed, p.letter.MessageID)
+	}
+	return replayed, nil
+}
+
+func (a *DeadLetterAdmin) Purge(queue string, ids []string) (int, error) {
+	stream, err := a.stream(queue)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.b.opts.PublishTimeout)
+	defer cancel()
+
+	if len(ids) == 0 {
+		info, err := stream.Info(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if err := stream.Purge(ctx); err != nil {
+			return 0, err
+		}
+		return int(info.State.Msgs), nil
+	}
+
+	msgs, err := a.scan(queue, maxDeadLetterScan)
+	if err != nil {
+		return 0, err
+	}
+	selected := toSet(ids)
+	purged := 0
+	for _, p := range msgs {
+		if !selected[p.letter.MessageID] {
+			continue
+		}
+		if err := stream.DeleteMsg(ctx, p.seq); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (a *DeadLetterAdmin) stream(queue string) (jetstream.Stream, error) {
+	for _, q := range a.b.queues {
+		if q.ParkingQueue == queue {
+			ctx, cancel := context.WithTimeout(context.Background(), a.b.opts.PublishTimeout)
+			defer cancel()
+			return a.b.js.Stream(ctx, streamName(queue))
+		}
+	}
+	return nil, ErrUnknownQueue
+}
+
+// scan reads up to limit parked messages in stream order
+func (a *DeadLetterAdmin) scan(queue string, limit int) ([]parked, error) {
+	if limit <= 0 || limit > maxDeadLetterScan {
+		limit = maxDeadLetterScan
+	}
+	stream, err := a.stream(queue)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.b.opts.PublishTimeout)
+	defer cancel()
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []parked
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq && len(out) < limit && info.State.Msgs > 0; seq++ {
+		raw, err := stream.GetMsg(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			continue // deleted by an earlier replay or purge
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, parked{seq: seq, letter: toDeadLetter(raw)})
+	}
+	return out, nil
+}
+
+func toDeadLetter(raw *jetstream.RawStreamMsg) messaging.DeadLetter {
+	headers := map[string]interface{}{}
+	for k, v := range raw.Header {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+
+	dl := messaging.DeadLetter{
+		MessageID:  raw.Header.Get(headerMessageID),
+		RoutingKey: raw.Header.Get(headerOrigSubject),
+		Body:       raw.Data,
+		Headers:    headers,
+		Reason:     raw.Header.Get(headerDeathReason),
+		Timestamp:  raw.Time,
+	}
+	if dl.MessageID == "" {
+		dl.MessageID = "seq-" + strconv.FormatUint(raw.Sequence, 10)
+	}
+	dl.Attempts, _ = strconv.Atoi(raw.Header.Get(headerRetryCount))
+	return dl
+}
+
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,53 @@
+package natsbroker
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+)
+
+const embeddedStartTimeout = 10 * time.Second
+
+var ErrEmbeddedNotReady = errors.New("natsbroker: embedded server did not start")
+
+// StartEmbedded runs a JetStream-enabled NATS server inside this process and
+// connects to it without a network listener. storeDir keeps the streams across
+// restarts; an empty storeDir uses a temporary directory.
+func StartEmbedded(storeDir string, opts Options) (*Broker, error) {
+	ns, err := server.NewServer(&server.Options{
+		ServerName: "golang-api-service",
+		JetStream:  true,
+		StoreDir:   storeDir,
+		DontListen: true,
+		NoSigs:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go ns.Start()
+	if !ns.ReadyForConnections(embeddedStartTimeout) {
+		ns.Shutdown()
+		return nil, ErrEmbeddedNotReady
+	}
+
+	nc, err := nats.Connect(nats.DefaultURL, nats.InProcessServer(ns), nats.MaxReconnects(-1))
+	if err != nil {
+		ns.Shutdown()
+		return nil, err
+	}
+
+	b, err := newBroker(nc, opts)
+	if err != nil {
+		nc.Close()
+		ns.Shutdown()
+		return nil, err
+	}
+	b.onClose = func() {
+		ns.Shutdown()
+		ns.WaitForShutdown()
+	}
+	return b, nil
+}
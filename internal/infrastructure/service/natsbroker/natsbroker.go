@@ -0,0 +1,427 @@
+// Package natsbroker is a NATS JetStream messaging.BrokerPort for deployments
+// without RabbitMQ. It mirrors the RabbitMQ adapter's semantics:
+//
+//   - an exchange and routing key map to the subject "<exchange>.<routingKey>"
+//   - each consumed queue is a work-queue stream with one durable consumer
+//   - Nack with requeue redelivers after the queue's retry delays, and parks
+//     the message in the queue's parking stream once its attempts are used up
+//   - Nack without requeue parks the message straight away
+//
+// One difference remains: deliveries still unacked when a subscription closes
+// come back after AckWait rather than immediately.
+package natsbroker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	defaultPublishTimeout = 5 * time.Second
+	defaultAckWait        = 30 * time.Second
+	readyPollInterval     = 200 * time.Millisecond
+
+	headerMessageID    = "X-Message-Id"
+	headerContentType  = "Content-Type"
+	headerCorrelation  = "X-Correlation-Id"
+	headerReplyTo      = "X-Reply-To"
+	headerOrigSubject  = "X-Original-Subject"
+	headerDeathReason  = "X-Death-Reason"
+	headerRetryCount   = "X-Retry-Count"
+	headerDeadLetterAt = "X-Dead-Lettered-At"
+)
+
+var (
+	ErrUnknownQueue    = errors.New("natsbroker: queue not configured")
+	errForeignDelivery = errors.New("natsbroker: delivery was not produced by this broker")
+)
+
+// QueueConfig declares one consumed queue
+type QueueConfig struct {
+	Queue    string   // stream and durable consumer name
+	Subjects []string // subjects captured by the stream, see Subject
+	// RetryDelays are the redelivery delays per attempt; attempts beyond the
+	// last reuse the last delay. Empty redelivers immediately.
+	RetryDelays []time.Duration
+	// MaxAttempts is how many retries a message gets before it is parked
+	// (0 for unlimited)
+	MaxAttempts int
+	// ParkingQueue receives rejected and exhausted messages; empty drops them
+	ParkingQueue string
+	// AckWait is how long a delivery may stay unacked before it is
+	// redelivered (default 30s)
+	AckWait time.Duration
+}
+
+type Options struct {
+	Queues         []QueueConfig
+	PublishTimeout time.Duration // default 5s
+}
+
+type Broker struct {
+	nc     *nats.Conn
+	js     jetstream.JetStream
+	opts   Options
+	queues map[string]QueueConfig
+	closed chan struct{} // closed once the connection is closed for good
+	// onClose runs after the connection is closed, e.g. to stop an embedded server
+	onClose func()
+}
+
+// Subject maps an exchange and routing key to a NATS subject
+func Subject(exchange, routingKey string) string {
+	if routingKey == "" {
+		return exchange
+	}
+	return exchange + "." + routingKey
+}
+
+// Connect dials a NATS server and declares the configured streams
+func Connect(url string, opts Options) (*Broker, error) {
+	nc, err := nats.Connect(url, nats.MaxReconnects(-1), nats.Name("golang-api-service"))
+	if err != nil {
+		return nil, err
+	}
+	b, err := newBroker(nc, opts)
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+func newBroker(nc *nats.Conn, opts Options) (*Broker, error) {
+	if opts.PublishTimeout <= 0 {
+		opts.PublishTimeout = defaultPublishTimeout
+	}
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Broker{nc: nc, js: js, opts: opts, queues: map[string]QueueConfig{}, closed: make(chan struct{})}
+	var once sync.Once
+	nc.SetClosedHandler(func(*nats.Conn) { once.Do(func() { close(b.closed) }) })
+	if nc.IsClosed() {
+		once.Do(func() { close(b.closed) })
+	}
+	for _, q := range opts.Queues {
+		if q.AckWait <= 0 {
+			q.AckWait = defaultAckWait
+		}
+		b.queues[q.Queue] = q
+	}
+	if err := b.declare(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// declare creates or updates the streams; unchanged streams are left alone
+func (b *Broker) declare() error {
+	ctx, cancel := context.WithTimeout(context.Background(), b.opts.PublishTimeout)
+	defer cancel()
+
+	for _, q := range b.queues {
+		if _, err := b.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:      streamName(q.Queue),
+			Subjects:  q.Subjects,
+			Retention: jetstream.WorkQueuePolicy,
+			Storage:   jetstream.FileStorage,
+		}); err != nil {
+			return fmt.Errorf("declare stream %s: %w", q.Queue, err)
+		}
+		if q.ParkingQueue == "" {
+			continue
+		}
+		if _, err := b.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+			Name:      streamName(q.ParkingQueue),
+			Subjects:  []string{q.ParkingQueue},
+			Retention: jetstream.LimitsPolicy,
+			Storage:   jetstream.FileStorage,
+		}); err != nil {
+			return fmt.Errorf("declare stream %s: %w", q.ParkingQueue, err)
+		}
+	}
+	return nil
+}
+
+// Publish with Mandatory stores the message in JetStream and waits for the
+// ack; it fails with messaging.ErrUnroutable when no stream captures the
+// subject. Other messages are plain NATS publishes, like fanout events.
+func (b *Broker) Publish(ctx context.Context, exchange, routingKey string, msg messaging.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if !b.IsReady() {
+		return messaging.ErrNotConnected
+	}
+
+	m := nats.NewMsg(Subject(exchange, routingKey))
+	m.Data = msg.Body
+	for k, v := range msg.Headers {
+		m.Header.Set(k, fmt.Sprint(v))
+	}
+	id := msg.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	m.Header.Set(headerMessageID, id)
+	if msg.ContentType != "" {
+		m.Header.Set(headerContentType, msg.ContentType)
+	}
+	if msg.CorrelationID != "" {
+		m.Header.Set(headerCorrelation, msg.CorrelationID)
+	}
+	if msg.ReplyTo != "" {
+		m.Header.Set(headerReplyTo, msg.ReplyTo)
+	}
+
+	if !msg.Mandatory {
+		return b.nc.PublishMsg(m)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.opts.PublishTimeout)
+	defer cancel()
+	if _, err := b.js.PublishMsg(ctx, m); err != nil {
+		switch {
+		case errors.Is(err, jetstream.ErrNoStreamResponse), errors.Is(err, nats.ErrNoResponders):
+			return fmt.Errorf("%w: subject=%s messageId=%s", messaging.ErrUnroutable, m.Subject, id)
+		case errors.Is(err, context.DeadlineExceeded):
+			return fmt.Errorf("%w: subject=%s messageId=%s", messaging.ErrConfirmTimeout, m.Subject, id)
+		}
+		return err
+	}
+	return nil
+}
+
+func (b *Broker) Subscribe(ctx context.Context, queue string, opts messaging.SubscribeOptions) (messaging.Subscription, error) {
+	cfg, ok := b.queues[queue]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownQueue, queue)
+	}
+
+	prefetch := opts.Prefetch
+	if prefetch <= 0 {
+		prefetch = 1
+	}
+
+	consumer, err := b.js.CreateOrUpdateConsumer(ctx, streamName(queue), jetstream.ConsumerConfig{
+		Durable:       streamName(queue),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       cfg.AckWait,
+		MaxAckPending: prefetch,
+		MaxDeliver:    -1, // parking is done by Nack
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := consumer.Messages(jetstream.PullMaxMessages(prefetch))
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscription{iter: iter, out: make(chan messaging.Delivery), done: make(chan struct{})}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			iter.Stop()
+		case <-sub.done:
+		}
+	}()
+
+	go func() {
+		defer close(sub.out)
+		for {
+			msg, err := iter.Next()
+			if err != nil {
+				// ErrMsgIteratorClosed after Stop, or a dropped connection
+				return
+			}
+			select {
+			case sub.out <- toDelivery(queue, msg):
+			case <-sub.done:
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+func (b *Broker) Ack(d messaging.Delivery) error {
+	msg, ok := d.Handle.(jetstream.Msg)
+	if !ok {
+		return errForeignDelivery
+	}
+	return msg.Ack()
+}
+
+// Nack with requeue redelivers after the next retry delay, or parks the
+// message once MaxAttempts retries are used up; without requeue it is parked
+// straight away.
+func (b *Broker) Nack(d messaging.Delivery, requeue bool) error {
+	msg, ok := d.Handle.(jetstream.Msg)
+	if !ok {
+		return errForeignDelivery
+	}
+	cfg := b.queues[d.Queue]
+
+	if requeue && (cfg.MaxAttempts <= 0 || d.Attempts < cfg.MaxAttempts) {
+		if len(cfg.RetryDelays) == 0 {
+			return msg.Nak()
+		}
+		tier := d.Attempts
+		if tier >= len(cfg.RetryDelays) {
+			tier = len(cfg.RetryDelays) - 1
+		}
+		return msg.NakWithDelay(cfg.RetryDelays[tier])
+	}
+
+	reason := "rejected"
+	if requeue {
+		reason = "maxattempts"
+	}
+	return b.park(msg, cfg, reason, d.Attempts)
+}
+
+// park copies the message to the parking stream and then removes it from the
+// work queue. If the copy fails the message is redelivered later instead.
+func (b *Broker) park(msg jetstream.Msg, cfg QueueConfig, reason string, attempts int) error {
+	if cfg.ParkingQueue == "" {
+		return msg.Term()
+	}
+
+	m := nats.NewMsg(cfg.ParkingQueue)
+	m.Data = msg.Data()
+	for k, v := range msg.Headers() {
+		m.Header[k] = v
+	}
+	m.Header.Set(headerOrigSubject, msg.Subject())
+	m.Header.Set(headerDeathReason, reason)
+	m.Header.Set(headerRetryCount, strconv.Itoa(attempts))
+	m.Header.Set(headerDeadLetterAt, time.Now().UTC().Format(time.RFC3339))
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.opts.PublishTimeout)
+	defer cancel()
+	if _, err := b.js.PublishMsg(ctx, m); err != nil {
+		_ = msg.Nak()
+		return err
+	}
+	return msg.Ack()
+}
+
+// SubscribeFanout delivers every message on the exchange's subject to handler
+// until ctx is done. Each instance gets its own copy.
+func (b *Broker) SubscribeFanout(ctx context.Context, exchange string, handler func(body []byte)) error {
+	sub, err := b.nc.Subscribe(exchange, func(m *nats.Msg) {
+		handler(m.Data)
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = sub.Unsubscribe() }()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-b.closed:
+		return nats.ErrConnectionClosed
+	}
+}
+
+func (b *Broker) WaitReady(ctx context.Context) error {
+	ticker := time.NewTicker(readyPollInterval)
+	defer ticker.Stop()
+	for !b.IsReady() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-b.closed:
+			return nats.ErrConnectionClosed
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+func (b *Broker) IsReady() bool {
+	return b != nil && b.nc.IsConnected()
+}
+
+func (b *Broker) Close() error {
+	if err := b.nc.Drain(); err != nil {
+		b.nc.Close()
+	}
+	<-b.closed
+	if b.onClose != nil {
+		b.onClose()
+	}
+	return nil
+}
+
+func toDelivery(queue string, msg jetstream.Msg) messaging.Delivery {
+	headers := map[string]interface{}{}
+	for k, v := range msg.Headers() {
+		if len(v) > 0 {
+			headers[k] = v[0]
+		}
+	}
+
+	d := messaging.Delivery{
+		Message: messaging.Message{
+			ID:            msg.Headers().Get(headerMessageID),
+			ContentType:   msg.Headers().Get(headerContentType),
+			Headers:       headers,
+			Body:          msg.Data(),
+			CorrelationID: msg.Headers().Get(headerCorrelation),
+			ReplyTo:       msg.Headers().Get(headerReplyTo),
+		},
+		Queue:      queue,
+		RoutingKey: msg.Subject(),
+		Handle:     msg,
+	}
+	if meta, err := msg.Metadata(); err == nil {
+		d.Timestamp = meta.Timestamp
+		d.Attempts = int(meta.NumDelivered) - 1
+		d.Redelivered = meta.NumDelivered > 1
+	}
+	return d
+}
+
+// streamName turns a queue name into a valid stream name
+func streamName(queue string) string {
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_").Replace(queue)
+}
+
+type subscription struct {
+	iter      jetstream.MessagesContext
+	out       chan messaging.Delivery
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *subscription) Deliveries() <-chan messaging.Delivery {
+	return s.out
+}
+
+// Close stops pulling; unacked deliveries are redelivered after AckWait
+func (s *subscription) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.iter.Stop()
+	})
+	return nil
+}
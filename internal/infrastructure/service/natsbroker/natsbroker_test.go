@@ -0,0 +1,35 @@
+package natsbroker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	b, err := StartEmbedded(t.TempDir(), Options{
+		Queues: []QueueConfig{{
+			Queue:        "check_queue",
+			Subjects:     []string{Subject("check.exchange", "check.created")},
+			RetryDelays:  []time.Duration{100 * time.Millisecond},
+			MaxAttempts:  2,
+			ParkingQueue: "check_queue.parking",
+			AckWait:      time.Second,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = b.Close() })
+
+	conformance.Test(t, conformance.Fixture{
+		Broker:            b,
+		Exchange:          "check.exchange",
+		RoutingKey:        "check.created",
+		UnboundRoutingKey: "check.unbound",
+		Queue:             "check_queue",
+		MaxAttempts:       2,
+		Redelivery:        2 * time.Second,
+	})
+}
@@ -0,0 +1,46 @@
+package natsbroker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// QueueStats reports the messages the queue's consumer has not delivered yet.
+// JetStream does not count pull subscribers, so Consumers is 1 while a worker
+// is pulling or holds unacked messages and 0 otherwise.
+func (b *Broker) QueueStats(ctx context.Context, queue string) (messaging.QueueStats, error) {
+	if _, ok := b.queues[queue]; !ok {
+		return messaging.QueueStats{}, fmt.Errorf("%w: %s", ErrUnknownQueue, queue)
+	}
+
+	consumer, err := b.js.Consumer(ctx, streamName(queue), streamName(queue))
+	if errors.Is(err, jetstream.ErrConsumerNotFound) {
+		// Nobody has subscribed yet; everything in the stream is waiting
+		stream, err := b.js.Stream(ctx, streamName(queue))
+		if err != nil {
+			return messaging.QueueStats{}, err
+		}
+		info, err := stream.Info(ctx)
+		if err != nil {
+			return messaging.QueueStats{}, err
+		}
+		return messaging.QueueStats{Messages: int(info.State.Msgs)}, nil
+	}
+	if err != nil {
+		return messaging.QueueStats{}, err
+	}
+
+	info, err := consumer.Info(ctx)
+	if err != nil {
+		return messaging.QueueStats{}, err
+	}
+	stats := messaging.QueueStats{Messages: int(info.NumPending)}
+	if info.NumWaiting > 0 || info.NumAckPending > 0 {
+		stats.Consumers = 1
+	}
+	return stats, nil
+}
@@ -0,0 +1,23 @@
+package qris
+
+import "fmt"
+
+// crc16 computes CRC-16/CCITT-FALSE (poly 0x1021, init 0xFFFF) as required by EMVCo
+func crc16(data string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(data); i++ {
+		crc ^= uint16(data[i]) << 8
+		for b := 0; b < 8; b++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func checksum(data string) string {
+	return fmt.Sprintf("%04X", crc16(data))
+}
@@ -0,0 +1,31 @@
+package qris
+
+import "testing"
+
+// emvcoSample is the merchant-presented mode example from EMVCo QRCPS Appendix A
+const emvcoSample = "00020101021229300012D156000000000510A93FO3230Q31280012D15600000001030812345678520441115802CN5914BEST TRANSPORT6007BEIJING64200002ZH0104最佳运输0202北京540523.7253031565502016233030412340603***0708A60086670902ME91320016A0112233449988770708123456786304A13A"
+
+func TestCRC16(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want uint16
+	}{
+		{"empty", "", 0xFFFF},
+		{"check value", "123456789", 0x29B1},
+		{"emvco sample", emvcoSample[:len(emvcoSample)-4], 0xA13A},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := crc16(tt.data); got != tt.want {
+				t.Errorf("crc16 = %04X, want %04X", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	if got := checksum("A"); got != "B915" {
+		t.Errorf("checksum(A) = %s, want B915", got)
+	}
+}
@@ -0,0 +1,246 @@
+package qris
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// EMVCo merchant-presented mode tags used by QRIS
+const (
+	tagPayloadFormat     = "00"
+	tagPointOfInitiation = "01"
+	tagMCC               = "52"
+	tagCurrency          = "53"
+	tagAmount            = "54"
+	tagCountryCode       = "58"
+	tagMerchantName      = "59"
+	tagMerchantCity      = "60"
+	tagPostalCode        = "61"
+	tagAdditionalData    = "62"
+	tagCRC               = "63"
+
+	// Sub-tags of a merchant account information template (26-51)
+	subTagGUID       = "00"
+	subTagPAN        = "01"
+	subTagMerchantID = "02"
+	subTagCriteria   = "03"
+
+	// Sub-tags of the additional data template (62)
+	subTagBillNumber     = "01"
+	subTagReferenceLabel = "05"
+	subTagTerminalLabel  = "07"
+
+	PointOfInitiationStatic  = "11"
+	PointOfInitiationDynamic = "12"
+
+	CurrencyIDR = "360"
+	CountryID   = "ID"
+)
+
+var (
+	ErrMalformed     = errors.New("qris: malformed payload")
+	ErrInvalidCRC    = errors.New("qris: invalid CRC")
+	ErrFieldTooLong  = errors.New("qris: field value too long")
+	ErrMissingField  = errors.New("qris: missing mandatory field")
+	ErrInvalidFormat = errors.New("qris: unsupported payload format")
+)
+
+// MerchantAccount is one merchant account information template (tags 26-51)
+type MerchantAccount struct {
+	GUID       string `json:"guid"`
+	PAN        string `json:"pan,omitempty"`
+	MerchantID string `json:"merchantId,omitempty"`
+	Criteria   string `json:"criteria,omitempty"`
+}
+
+// Payload is a decoded EMVCo merchant-presented QR payload
+type Payload struct {
+	PointOfInitiation    string                     `json:"pointOfInitiation"`
+	MerchantAccounts     map[string]MerchantAccount `json:"merchantAccounts"` // keyed by tag
+	MerchantCategoryCode string                     `json:"merchantCategoryCode"`
+	Currency             string                     `json:"currency"`
+	Amount               string                     `json:"amount,omitempty"`
+	CountryCode          string                     `json:"countryCode"`
+	MerchantName         string                     `json:"merchantName"`
+	MerchantCity         string                     `json:"merchantCity"`
+	PostalCode           string                     `json:"postalCode,omitempty"`
+	BillNumber           string                     `json:"billNumber,omitempty"`
+	ReferenceLabel       string                     `json:"referenceLabel,omitempty"`
+	TerminalLabel        string                     `json:"terminalLabel,omitempty"`
+}
+
+// IsDynamic reports whether the payload is meant for a single payment
+func (p *Payload) IsDynamic() bool {
+	return p.PointOfInitiation == PointOfInitiationDynamic
+}
+
+// Encode serializes the payload in tag order and appends the CRC
+func Encode(p *Payload) (string, error) {
+	if p.MerchantName == "" || p.MerchantCity == "" || p.MerchantCategoryCode == "" || len(p.MerchantAccounts) == 0 {
+		return "", ErrMissingField
+	}
+
+	var b strings.Builder
+	fields := []struct{ tag, value string }{
+		{tagPayloadFormat, "01"},
+		{tagPointOfInitiation, p.PointOfInitiation},
+	}
+
+	tags := make([]string, 0, len(p.MerchantAccounts))
+	for tag := range p.MerchantAccounts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		ma := p.MerchantAccounts[tag]
+		tpl, err := template(
+			subTagGUID, ma.GUID,
+			subTagPAN, ma.PAN,
+			subTagMerchantID, ma.MerchantID,
+			subTagCriteria, ma.Criteria,
+		)
+		if err != nil {
+			return "", err
+		}
+		fields = append(fields, struct{ tag, value string }{tag, tpl})
+	}
+
+	additional, err := template(
+		subTagBillNumber, p.BillNumber,
+		subTagReferenceLabel, p.ReferenceLabel,
+		subTagTerminalLabel, p.TerminalLabel,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	fields = append(fields, []struct{ tag, value string }{
+		{tagMCC, p.MerchantCategoryCode},
+		{tagCurrency, p.Currency},
+		{tagAmount, p.Amount},
+		{tagCountryCode, p.CountryCode},
+		{tagMerchantName, p.MerchantName},
+		{tagMerchantCity, p.MerchantCity},
+		{tagPostalCode, p.PostalCode},
+		{tagAdditionalData, additional},
+	}...)
+
+	for _, f := range fields {
+		if err := writeTLV(&b, f.tag, f.value); err != nil {
+			return "", err
+		}
+	}
+
+	// CRC covers everything up to and including its own tag and length
+	b.WriteString(tagCRC + "04")
+	return b.String() + checksum(b.String()), nil
+}
+
+// Decode verifies the CRC and parses the payload
+func Decode(raw string) (*Payload, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 8 || raw[len(raw)-8:len(raw)-4] != tagCRC+"04" {
+		return nil, ErrMalformed
+	}
+	if !strings.EqualFold(checksum(raw[:len(raw)-4]), raw[len(raw)-4:]) {
+		return nil, ErrInvalidCRC
+	}
+
+	fields, err := parseTLV(raw[:len(raw)-8])
+	if err != nil {
+		return nil, err
+	}
+	if fields[tagPayloadFormat] != "01" {
+		return nil, ErrInvalidFormat
+	}
+
+	p := &Payload{
+		PointOfInitiation:    fields[tagPointOfInitiation],
+		MerchantAccounts:     map[string]MerchantAccount{},
+		MerchantCategoryCode: fields[tagMCC],
+		Currency:             fields[tagCurrency],
+		Amount:               fields[tagAmount],
+		CountryCode:          fields[tagCountryCode],
+		MerchantName:         fields[tagMerchantName],
+		MerchantCity:         fields[tagMerchantCity],
+		PostalCode:           fields[tagPostalCode],
+	}
+
+	for tag, value := range fields {
+		n, _ := strconv.Atoi(tag)
+		if n < 26 || n > 51 {
+			continue
+		}
+		sub, err := parseTLV(value)
+		if err != nil {
+			return nil, err
+		}
+		p.MerchantAccounts[tag] = MerchantAccount{
+			GUID:       sub[subTagGUID],
+			PAN:        sub[subTagPAN],
+			MerchantID: sub[subTagMerchantID],
+			Criteria:   sub[subTagCriteria],
+		}
+	}
+
+	if value, ok := fields[tagAdditionalData]; ok {
+		sub, err := parseTLV(value)
+		if err != nil {
+			return nil, err
+		}
+		p.BillNumber = sub[subTagBillNumber]
+		p.ReferenceLabel = sub[subTagReferenceLabel]
+		p.TerminalLabel = sub[subTagTerminalLabel]
+	}
+
+	if p.MerchantName == "" || p.MerchantCity == "" || p.Currency == "" || len(p.MerchantAccounts) == 0 {
+		return nil, ErrMissingField
+	}
+	return p, nil
+}
+
+// template builds a nested TLV value from tag/value pairs, skipping empty values
+func template(pairs ...string) (string, error) {
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if err := writeTLV(&b, pairs[i], pairs[i+1]); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// writeTLV counts the length in characters, as EMVCo does for non-ASCII values
+func writeTLV(b *strings.Builder, tag, value string) error {
+	if value == "" {
+		return nil
+	}
+	n := utf8.RuneCountInString(value)
+	if n > 99 {
+		return fmt.Errorf("%w: tag %s", ErrFieldTooLong, tag)
+	}
+	fmt.Fprintf(b, "%s%02d%s", tag, n, value)
+	return nil
+}
+
+func parseTLV(s string) (map[string]string, error) {
+	r := []rune(s)
+	fields := map[string]string{}
+	for i := 0; i < len(r); {
+		if i+4 > len(r) {
+			return nil, ErrMalformed
+		}
+		tag := string(r[i : i+2])
+		n, err := strconv.Atoi(string(r[i+2 : i+4]))
+		if err != nil || n < 0 || i+4+n > len(r) {
+			return nil, ErrMalformed
+		}
+		fields[tag] = string(r[i+4 : i+4+n])
+		i += 4 + n
+	}
+	return fields, nil
+}
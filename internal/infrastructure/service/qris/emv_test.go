@@ -0,0 +1,193 @@
+package qris
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    *Payload
+		wantErr error
+	}{
+		{
+			name: "emvco sample",
+			raw:  emvcoSample,
+			want: &Payload{
+				PointOfInitiation: PointOfInitiationDynamic,
+				MerchantAccounts: map[string]MerchantAccount{
+					"29": {GUID: "D15600000000"},
+					"31": {GUID: "D15600000001", Criteria: "12345678"},
+				},
+				MerchantCategoryCode: "4111",
+				Currency:             "156",
+				Amount:               "23.72",
+				CountryCode:          "CN",
+				MerchantName:         "BEST TRANSPORT",
+				MerchantCity:         "BEIJING",
+				TerminalLabel:        "A6008667",
+			},
+		},
+		{
+			name: "lowercase crc and surrounding space",
+			raw:  " " + emvcoSample[:len(emvcoSample)-4] + "a13a\n",
+			want: &Payload{
+				PointOfInitiation: PointOfInitiationDynamic,
+				MerchantAccounts: map[string]MerchantAccount{
+					"29": {GUID: "D15600000000"},
+					"31": {GUID: "D15600000001", Criteria: "12345678"},
+				},
+				MerchantCategoryCode: "4111",
+				Currency:             "156",
+				Amount:               "23.72",
+				CountryCode:          "CN",
+				MerchantName:         "BEST TRANSPORT",
+				MerchantCity:         "BEIJING",
+				TerminalLabel:        "A6008667",
+			},
+		},
+		{
+			name:    "edited amount keeps the old crc",
+			raw:     strings.Replace(emvcoSample, "540523.72", "540513.72", 1),
+			wantErr: ErrInvalidCRC,
+		},
+		{
+			name:    "too short",
+			raw:     "6304",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "no crc tag",
+			raw:     "000201010211",
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "length runs past the end",
+			raw:     withCRC("000201010211599"),
+			wantErr: ErrMalformed,
+		},
+		{
+			name:    "unsupported format",
+			raw:     withCRC("00020201021126100006ABCDEF5204541153033605802ID5901A6001B"),
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "missing merchant name",
+			raw:     withCRC("00020101021126100006ABCDEF5204541153033605802ID6001B"),
+			wantErr: ErrMissingField,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.raw)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	static := &Payload{
+		PointOfInitiation: PointOfInitiationStatic,
+		MerchantAccounts: map[string]MerchantAccount{
+			"26": {GUID: "ID.CO.GOLANGAPI.WWW", PAN: "1234567890", MerchantID: "7"},
+		},
+		MerchantCategoryCode: "5499",
+		Currency:             CurrencyIDR,
+		CountryCode:          CountryID,
+		MerchantName:         "Toko Maju",
+		MerchantCity:         "Jakarta",
+	}
+	dynamic := *static
+	dynamic.PointOfInitiation = PointOfInitiationDynamic
+	dynamic.Amount = "15000"
+	dynamic.BillNumber = "INV-1"
+	dynamic.ReferenceLabel = "ref0001"
+	dynamic.TerminalLabel = "T1"
+	unicode := *static
+	unicode.MerchantName = "最佳运输"
+
+	tests := []struct {
+		name    string
+		payload *Payload
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "static",
+			payload: static,
+			want:    withCRC("00020101021126420019ID.CO.GOLANGAPI.WWW01101234567890020175204549953033605802ID5909Toko Maju6007Jakarta"),
+		},
+		{
+			name:    "dynamic",
+			payload: &dynamic,
+			want:    withCRC("00020101021226420019ID.CO.GOLANGAPI.WWW0110123456789002017520454995303360540515000" + "5802ID5909Toko Maju6007Jakarta62260105INV-10507ref00010702T1"),
+		},
+		{
+			name:    "length counts characters",
+			payload: &unicode,
+			want:    withCRC("00020101021126420019ID.CO.GOLANGAPI.WWW01101234567890020175204549953033605802ID5904最佳运输6007Jakarta"),
+		},
+		{
+			name:    "missing merchant name",
+			payload: &Payload{MerchantCity: "Jakarta", MerchantCategoryCode: "5499", MerchantAccounts: static.MerchantAccounts},
+			wantErr: ErrMissingField,
+		},
+		{
+			name: "field too long",
+			payload: &Payload{
+				MerchantAccounts:     static.MerchantAccounts,
+				MerchantCategoryCode: "5499",
+				MerchantName:         "Toko",
+				MerchantCity:         "Jakarta",
+				BillNumber:           strings.Repeat("x", 100),
+			},
+			wantErr: ErrFieldTooLong,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.payload)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Encode =\n%s\nwant\n%s", got, tt.want)
+			}
+
+			decoded, err := Decode(got)
+			if err != nil {
+				t.Fatalf("decode own output: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, tt.payload) {
+				t.Errorf("round trip =\n%+v\nwant\n%+v", decoded, tt.payload)
+			}
+		})
+	}
+}
+
+// withCRC appends the CRC tag and checksum to a payload body
+func withCRC(body string) string {
+	body += tagCRC + "04"
+	return body + checksum(body)
+}
@@ -0,0 +1,8 @@
+package qris
+
+import qrcode "github.com/skip2/go-qrcode"
+
+// RenderPNG draws the payload as a square PNG of the given size in pixels
+func RenderPNG(payload string, size int) ([]byte, error) {
+	return qrcode.Encode(payload, qrcode.Medium, size)
+}
@@ -0,0 +1,177 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var errForeignDelivery = errors.New("rabbitmq: delivery was not produced by this broker")
+
+// Broker adapts RabbitMQService to messaging.BrokerPort. Queues with a
+// topology get its retry tiers and parking queue on Nack.
+type Broker struct {
+	r          *RabbitMQService
+	topologies map[string]TopologyConfig // keyed by queue
+}
+
+func NewBroker(r *RabbitMQService, topologies ...TopologyConfig) *Broker {
+	b := &Broker{r: r, topologies: map[string]TopologyConfig{}}
+	for _, t := range topologies {
+		b.topologies[t.Queue] = t
+	}
+	return b
+}
+
+func (b *Broker) Publish(ctx context.Context, exchange, routingKey string, msg messaging.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	contentType := msg.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	return b.r.publishMsg(exchange, routingKey, msg.Mandatory, amqp.Publishing{
+		Headers:       amqp.Table(msg.Headers),
+		ContentType:   contentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     msg.ID,
+		CorrelationId: msg.CorrelationID,
+		ReplyTo:       msg.ReplyTo,
+		Timestamp:     timestamp,
+		Body:          msg.Body,
+	})
+}
+
+func (b *Broker) Subscribe(ctx context.Context, queue string, opts messaging.SubscribeOptions) (messaging.Subscription, error) {
+	ch, err := b.r.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	prefetch := opts.Prefetch
+	if prefetch <= 0 {
+		prefetch = 1
+	}
+	if err := ch.Qos(prefetch, 0, false); err != nil {
+		_ = ch.Close()
+		return nil, err
+	}
+
+	// An explicit tag, so the consumer can be cancelled by name below
+	tag := queue + "." + uuid.New().String()
+	msgs, err := ch.Consume(queue, tag, false, false, false, false, nil)
+	if err != nil {
+		_ = ch.Close()
+		return nil, err
+	}
+
+	sub := &subscription{ch: ch, out: make(chan messaging.Delivery), done: make(chan struct{})}
+	cfg := b.topologies[queue]
+
+	// Stop new deliveries when ctx is done; msgs closes once the broker
+	// confirms the cancel
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = ch.Cancel(tag, false)
+		case <-sub.done:
+		}
+	}()
+
+	go func() {
+		defer close(sub.out)
+		for d := range msgs {
+			delivery := messaging.Delivery{
+				Message: messaging.Message{
+					ID:            d.MessageId,
+					ContentType:   d.ContentType,
+					Headers:       map[string]interface{}(d.Headers),
+					Timestamp:     d.Timestamp,
+					Body:          d.Body,
+					CorrelationID: d.CorrelationId,
+					ReplyTo:       d.ReplyTo,
+				},
+				Queue:       queue,
+				RoutingKey:  d.RoutingKey,
+				Redelivered: d.Redelivered,
+				Attempts:    Attempts(d, cfg),
+				Handle:      d,
+			}
+			select {
+			case sub.out <- delivery:
+			case <-sub.done:
+				// Unforwarded deliveries are requeued when the channel closes
+				return
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+func (b *Broker) Ack(d messaging.Delivery) error {
+	raw, ok := d.Handle.(amqp.Delivery)
+	if !ok {
+		return errForeignDelivery
+	}
+	return raw.Ack(false)
+}
+
+// Nack with requeue goes through the queue's retry tiers (see RetryOrPark);
+// without requeue the message is rejected to the queue's DLX.
+func (b *Broker) Nack(d messaging.Delivery, requeue bool) error {
+	raw, ok := d.Handle.(amqp.Delivery)
+	if !ok {
+		return errForeignDelivery
+	}
+	if !requeue {
+		return raw.Reject(false)
+	}
+	_, err := b.r.RetryOrPark(raw, b.topologies[d.Queue])
+	return err
+}
+
+func (b *Broker) WaitReady(ctx context.Context) error {
+	return b.r.WaitReady(ctx)
+}
+
+func (b *Broker) IsReady() bool {
+	return b != nil && b.r.IsReady()
+}
+
+func (b *Broker) Close() error {
+	b.r.Close()
+	return nil
+}
+
+type subscription struct {
+	ch        *amqp.Channel
+	out       chan messaging.Delivery
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (s *subscription) Deliveries() <-chan messaging.Delivery {
+	return s.out
+}
+
+func (s *subscription) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		err = s.ch.Close()
+	})
+	return err
+}
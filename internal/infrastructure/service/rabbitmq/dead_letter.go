@@ -0,0 +1,239 @@
+package rabbitmq
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// maxDeadLetterScan bounds how many messages one admin call holds unacked
+const maxDeadLetterScan = 1000
+
+var ErrUnknownDeadLetterQueue = errors.New("rabbitmq: unknown dead-letter queue")
+
+// DeadLetterAdmin implements messaging.DeadLetterPort for the parking queues
+// of the given topologies. Several queues may share a parking queue; each
+// message is attributed to the queue it died in, so it is replayed to that
+// queue's exchange and routing key. Messages are fetched with basic.get and
+// left unacked; closing the channel puts back everything that was not
+// replayed or purged.
+type DeadLetterAdmin struct {
+	r          *RabbitMQService
+	topologies map[string][]TopologyConfig // keyed by parking queue
+}
+
+func NewDeadLetterAdmin(r *RabbitMQService, topologies ...TopologyConfig) *DeadLetterAdmin {
+	a := &DeadLetterAdmin{r: r, topologies: map[string][]TopologyConfig{}}
+	for _, t := range topologies {
+		if t.ParkingQueue != "" {
+			a.topologies[t.ParkingQueue] = append(a.topologies[t.ParkingQueue], t)
+		}
+	}
+	return a
+}
+
+func (a *DeadLetterAdmin) Peek(queue string, limit int) ([]messaging.DeadLetter, error) {
+	var out []messaging.DeadLetter
+	err := a.withMessages(queue, limit, func(_ *amqp.Channel, lanes []TopologyConfig, msgs []amqp.Delivery) error {
+		for _, d := range msgs {
+			out = append(out, toDeadLetter(d, lanes))
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (a *DeadLetterAdmin) Replay(queue string, ids []string, edits map[string][]byte) ([]string, error) {
+	selected := toSet(ids)
+	var replayed []string
+
+	err := a.withMessages(queue, maxDeadLetterScan, func(_ *amqp.Channel, lanes []TopologyConfig, msgs []amqp.Delivery) error {
+		for _, d := range msgs {
+			dl := toDeadLetter(d, lanes)
+			if !selected[dl.MessageID] {
+				continue
+			}
+
+			body := d.Body
+			if edited, ok := edits[dl.MessageID]; ok {
+				body = edited
+			}
+
+			// Start over with a clean retry budget
+			headers := amqp.Table{}
+			for k, v := range d.Headers {
+				headers[k] = v
+			}
+			delete(headers, "x-death")
+			delete(headers, "x-first-death-exchange")
+			delete(headers, "x-first-death-queue")
+			delete(headers, "x-first-death-reason")
+			delete(headers, "x-last-death-exchange")
+			delete(headers, "x-last-death-queue")
+			delete(headers, "x-last-death-reason")
+			delete(headers, retryCountHeader)
+			headers["x-replayed-at"] = time.Now().UTC().Format(time.RFC3339)
+
+			if err := a.r.publishMsg(dl.Exchange, dl.RoutingKey, true, amqp.Publishing{
+				Headers:       headers,
+				ContentType:   d.ContentType,
+				DeliveryMode:  amqp.Persistent,
+				MessageId:     dl.MessageID,
+				CorrelationId: d.CorrelationId,
+				Timestamp:     d.Timestamp,
+				Type:          d.Type,
+				Body:          body,
+			}); err != nil {
+				return err
+			}
+			if err := d.Ack(false); err != nil {
+				return err
+			}
+			replayed = append(replayed, dl.MessageID)
+		}
+		return nil
+	})
+	return replayed, err
+}
+
+func (a *DeadLetterAdmin) Purge(queue string, ids []string) (int, error) {
+	if _, ok := a.topologies[queue]; !ok {
+		return 0, ErrUnknownDeadLetterQueue
+	}
+
+	if len(ids) == 0 {
+		ch, err := a.r.Channel()
+		if err != nil {
+			return 0, err
+		}
+		defer ch.Close()
+		return ch.QueuePurge(queue, false)
+	}
+
+	selected := toSet(ids)
+	purged := 0
+	err := a.withMessages(queue, maxDeadLetterScan, func(_ *amqp.Channel, lanes []TopologyConfig, msgs []amqp.Delivery) error {
+		for _, d := range msgs {
+			if !selected[toDeadLetter(d, lanes).MessageID] {
+				continue
+			}
+			if err := d.Ack(false); err != nil {
+				return err
+			}
+			purged++
+		}
+		return nil
+	})
+	return purged, err
+}
+
+// withMessages fetches up to limit messages unacked and hands them to fn
+func (a *DeadLetterAdmin) withMessages(queue string, limit int, fn func(*amqp.Channel, []TopologyConfig, []amqp.Delivery) error) error {
+	lanes, ok := a.topologies[queue]
+	if !ok {
+		return ErrUnknownDeadLetterQueue
+	}
+	if limit <= 0 || limit > maxDeadLetterScan {
+		limit = maxDeadLetterScan
+	}
+
+	ch, err := a.r.Channel()
+	if err != nil {
+		return err
+	}
+	// Closing the channel requeues whatever fn did not ack
+	defer ch.Close()
+
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	if q.Messages < limit {
+		limit = q.Messages
+	}
+
+	msgs := make([]amqp.Delivery, 0, limit)
+	for len(msgs) < limit {
+		d, ok, err := ch.Get(queue, false)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		msgs = append(msgs, d)
+	}
+
+	return fn(ch, lanes, msgs)
+}
+
+// laneOf picks the queue among lanes that d died in, or the first one when
+// its headers do not say
+func laneOf(d amqp.Delivery, lanes []TopologyConfig) TopologyConfig {
+	if deaths, ok := d.Headers["x-death"].([]interface{}); ok {
+		for _, raw := range deaths {
+			death, ok := raw.(amqp.Table)
+			if !ok {
+				continue
+			}
+			for _, lane := range lanes {
+				if death["queue"] == lane.Queue {
+					return lane
+				}
+			}
+		}
+	}
+	return lanes[0]
+}
+
+func toDeadLetter(d amqp.Delivery, lanes []TopologyConfig) messaging.DeadLetter {
+	cfg := laneOf(d, lanes)
+	dl := messaging.DeadLetter{
+		MessageID:  d.MessageId,
+		Exchange:   cfg.Exchange,
+		RoutingKey: cfg.RoutingKey,
+		Body:       d.Body,
+		Headers:    map[string]interface{}(d.Headers),
+		Attempts:   Attempts(d, cfg),
+		Timestamp:  d.Timestamp,
+	}
+	if dl.MessageID == "" {
+		// Messages published before ids were assigned are keyed by their
+		// position in this scan
+		dl.MessageID = "tag-" + strconv.FormatUint(d.DeliveryTag, 10)
+	}
+	if reason, ok := d.Headers["x-first-death-reason"].(string); ok {
+		dl.Reason = reason
+	}
+
+	// Prefer where the message was published when it entered the main queue
+	if deaths, ok := d.Headers["x-death"].([]interface{}); ok {
+		for _, raw := range deaths {
+			death, ok := raw.(amqp.Table)
+			if !ok || death["queue"] != cfg.Queue {
+				continue
+			}
+			if ex, ok := death["exchange"].(string); ok && ex != "" {
+				dl.Exchange = ex
+			}
+			if keys, ok := death["routing-keys"].([]interface{}); ok && len(keys) > 0 {
+				if key, ok := keys[0].(string); ok {
+					dl.RoutingKey = key
+				}
+			}
+			break
+		}
+	}
+	return dl
+}
+
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
@@ -0,0 +1,260 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Delayed messages wait in a staging queue per delay whose TTL dead-letters
+// them into DelayReleaseExchange. The releaser on DelayReleaseQueue then
+// forwards each one to its real exchange unless it was cancelled meanwhile.
+const (
+	DelayReleaseExchange = "delay.release"
+	DelayReleaseQueue    = "delay.release"
+	delayStagingPrefix   = "delay.staging."
+
+	// delayStagingIdle is how long an unused staging queue outlives its TTL
+	// before the broker deletes it
+	delayStagingIdle = 10 * time.Minute
+
+	headerDelayExchange   = "x-delay-exchange"
+	headerDelayRoutingKey = "x-delay-routing-key"
+	headerDelayMandatory  = "x-delay-mandatory"
+)
+
+// ErrSealedExchange is returned by PublishDelayed for an exchange whose
+// messages must be sealed. Delayed messages are staged and released as plain
+// AMQP publishes, so they would reach sealed queues unsigned and be parked.
+var ErrSealedExchange = errors.New("rabbitmq: delayed publish to a sealed exchange")
+
+// Delayer implements messaging.DelayedPublisher with TTL and DLX staging
+// queues. Schedules are kept in the database so any instance can cancel a
+// message that another one staged.
+//
+// Messages bypass the broker port, so they are never sealed. PublishDelayed
+// rejects the exchanges listed as sealed with ErrSealedExchange.
+type Delayer struct {
+	r         *RabbitMQService
+	schedules repository.ScheduledMessageRepository
+	sealed    map[string]bool
+	logger    *log.Logger
+
+	mu       sync.Mutex
+	declared map[string]time.Time // staging queue -> last declare
+}
+
+func NewDelayer(r *RabbitMQService, schedules repository.ScheduledMessageRepository, sealedExchanges []string, logger *log.Logger) *Delayer {
+	sealed := map[string]bool{}
+	for _, exchange := range sealedExchanges {
+		sealed[exchange] = true
+	}
+	return &Delayer{
+		r:         r,
+		schedules: schedules,
+		sealed:    sealed,
+		logger:    logger,
+		declared:  map[string]time.Time{},
+	}
+}
+
+// StagingQueue names the staging queue for delay. Delays are rounded up to
+// whole seconds, with a minimum of one, to bound the number of queues.
+func StagingQueue(delay time.Duration) (string, time.Duration) {
+	ttl := delay.Truncate(time.Second)
+	if ttl < delay {
+		ttl += time.Second
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return delayStagingPrefix + ttl.String(), ttl
+}
+
+func (d *Delayer) PublishDelayed(ctx context.Context, exchange, routingKey string, msg messaging.Message, delay time.Duration) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	if d.sealed[exchange] {
+		return "", fmt.Errorf("%w: %s", ErrSealedExchange, exchange)
+	}
+	if msg.ID == "" {
+		msg.ID = uuid.New().String()
+	}
+	queue, ttl := StagingQueue(delay)
+
+	if err := d.schedules.Create(&entity.ScheduledMessage{
+		MessageID:  msg.ID,
+		Exchange:   exchange,
+		RoutingKey: routingKey,
+		DueAt:      time.Now().Add(ttl),
+		Status:     entity.ScheduledStatusPending,
+	}); err != nil {
+		return "", fmt.Errorf("record schedule: %w", err)
+	}
+
+	err := d.stage(queue, ttl, exchange, routingKey, msg)
+	if errors.Is(err, ErrUnroutable) {
+		// The staging queue is gone although we declared it; declare again
+		d.forget(queue)
+		err = d.stage(queue, ttl, exchange, routingKey, msg)
+	}
+	if err != nil {
+		if _, markErr := d.schedules.CompareAndSetStatus(msg.ID, entity.ScheduledStatusPending, entity.ScheduledStatusFailed); markErr != nil {
+			d.logger.Printf("delayed %s: mark failed: %v", msg.ID, markErr)
+		}
+		return "", err
+	}
+	return msg.ID, nil
+}
+
+func (d *Delayer) CancelDelayed(ctx context.Context, messageID string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	ok, err := d.schedules.CompareAndSetStatus(messageID, entity.ScheduledStatusPending, entity.ScheduledStatusCancelled)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return messaging.ErrNotScheduled
+	}
+	return nil
+}
+
+// Release forwards a message that left its staging queue. Cancelled and
+// already released messages are dropped. An error means the message should be
+// tried again.
+func (d *Delayer) Release(ctx context.Context, dl messaging.Delivery) error {
+	routingKey, ok := dl.Headers[headerDelayRoutingKey].(string)
+	if !ok {
+		d.logger.Printf("delayed %s: no target in headers, dropping", dl.ID)
+		return nil
+	}
+	exchange, _ := dl.Headers[headerDelayExchange].(string)
+	mandatory, _ := dl.Headers[headerDelayMandatory].(bool)
+
+	schedule, err := d.schedules.GetByMessageID(dl.ID)
+	if err != nil {
+		return err
+	}
+	if schedule != nil && schedule.Status != entity.ScheduledStatusPending {
+		d.logger.Printf("delayed %s: %s, dropping", dl.ID, schedule.Status)
+		return nil
+	}
+
+	headers := amqp.Table{}
+	for k, v := range dl.Headers {
+		if k == "x-death" || strings.HasPrefix(k, "x-first-death-") || strings.HasPrefix(k, "x-last-death-") || strings.HasPrefix(k, "x-delay-") {
+			continue
+		}
+		headers[k] = v
+	}
+
+	err = d.r.publishMsg(exchange, routingKey, mandatory, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   dl.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     dl.ID,
+		CorrelationId: dl.CorrelationID,
+		ReplyTo:       dl.ReplyTo,
+		Timestamp:     dl.Timestamp,
+		Body:          dl.Body,
+	})
+	status := entity.ScheduledStatusReleased
+	switch {
+	case errors.Is(err, ErrUnroutable):
+		// Retrying will not bind a queue; give up on the message
+		d.logger.Printf("delayed %s: unroutable to %s/%s, dropping", dl.ID, exchange, routingKey)
+		status = entity.ScheduledStatusFailed
+	case err != nil:
+		return err
+	}
+
+	if _, err := d.schedules.CompareAndSetStatus(dl.ID, entity.ScheduledStatusPending, status); err != nil {
+		d.logger.Printf("delayed %s: mark %s: %v", dl.ID, status, err)
+	}
+	return nil
+}
+
+func (d *Delayer) stage(queue string, ttl time.Duration, exchange, routingKey string, msg messaging.Message) error {
+	if err := d.declareStaging(queue, ttl); err != nil {
+		return fmt.Errorf("declare %s: %w", queue, err)
+	}
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[headerDelayExchange] = exchange
+	headers[headerDelayRoutingKey] = routingKey
+	headers[headerDelayMandatory] = msg.Mandatory
+
+	contentType := msg.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	timestamp := msg.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	return d.r.publishMsg("", queue, true, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   contentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     msg.ID,
+		CorrelationId: msg.CorrelationID,
+		ReplyTo:       msg.ReplyTo,
+		Timestamp:     timestamp,
+		Body:          msg.Body,
+	})
+}
+
+// declareStaging declares the staging queue unless that was done recently.
+// Redeclaring renews the queue's x-expires lease, so a queue in use is never
+// deleted with messages still waiting in it.
+func (d *Delayer) declareStaging(queue string, ttl time.Duration) error {
+	d.mu.Lock()
+	last, ok := d.declared[queue]
+	d.mu.Unlock()
+	if ok && time.Since(last) < delayStagingIdle/2 {
+		return nil
+	}
+
+	ch, err := d.r.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, amqp.Table{
+		"x-message-ttl":             ttl.Milliseconds(),
+		"x-dead-letter-exchange":    DelayReleaseExchange,
+		"x-dead-letter-routing-key": DelayReleaseQueue,
+		"x-expires":                 (ttl + delayStagingIdle).Milliseconds(),
+	}); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.declared[queue] = time.Now()
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *Delayer) forget(queue string) {
+	d.mu.Lock()
+	delete(d.declared, queue)
+	d.mu.Unlock()
+}
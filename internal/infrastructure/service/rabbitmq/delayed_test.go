@@ -0,0 +1,41 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+func TestStagingQueue(t *testing.T) {
+	tests := []struct {
+		delay time.Duration
+		queue string
+		ttl   time.Duration
+	}{
+		{0, "delay.staging.1s", time.Second},
+		{300 * time.Millisecond, "delay.staging.1s", time.Second},
+		{time.Second, "delay.staging.1s", time.Second},
+		{1500 * time.Millisecond, "delay.staging.2s", 2 * time.Second},
+		{15 * time.Minute, "delay.staging.15m0s", 15 * time.Minute},
+	}
+	for _, tt := range tests {
+		queue, ttl := StagingQueue(tt.delay)
+		if queue != tt.queue || ttl != tt.ttl {
+			t.Errorf("StagingQueue(%s) = %s, %s; want %s, %s", tt.delay, queue, ttl, tt.queue, tt.ttl)
+		}
+	}
+}
+
+func TestPublishDelayedRejectsSealedExchange(t *testing.T) {
+	// Rejected before the schedule is recorded or anything is staged
+	d := NewDelayer(nil, nil, []string{"topup.exchange"}, log.New(io.Discard, "", 0))
+	_, err := d.PublishDelayed(context.Background(), "topup.exchange", "topup", messaging.Message{Body: []byte(`{}`)}, time.Minute)
+	if !errors.Is(err, ErrSealedExchange) {
+		t.Fatalf("PublishDelayed = %v, want ErrSealedExchange", err)
+	}
+}
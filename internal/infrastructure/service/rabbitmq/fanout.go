@@ -0,0 +1,55 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+)
+
+// SubscribeFanout binds a private, auto-deleted queue to a fanout exchange so
+// every running instance receives its own copy of each message. It blocks
+// until ctx is done or the deliveries channel closes.
+func (r *RabbitMQService) SubscribeFanout(ctx context.Context, exchange string, handler func(body []byte)) error {
+	ch, err := r.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclare(
+		exchange,
+		"fanout",
+		true,  // durable
+		false, // auto-deleted
+		false,
+		false,
+		nil,
+	); err != nil {
+		return err
+	}
+
+	// Server-named, exclusive queue that disappears with this connection
+	q, err := ch.QueueDeclare("", false, true, true, false, nil)
+	if err != nil {
+		return err
+	}
+	if err := ch.QueueBind(q.Name, "", exchange, false, nil); err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(q.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d, ok := <-msgs:
+			if !ok {
+				return errors.New("rabbitmq: fanout deliveries closed")
+			}
+			handler(d.Body)
+		}
+	}
+}
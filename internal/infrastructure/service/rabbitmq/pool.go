@@ -0,0 +1,95 @@
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+)
+
+// channelPool lends confirm channels to one publisher at a time. At most size
+// channels are open; callers wait when all of them are busy. A size of zero
+// disables pooling and every publish opens and closes its own channel.
+type channelPool struct {
+	open func() (*confirmChannel, error)
+	size int
+	idle chan *confirmChannel
+	sem  chan struct{} // one token per open channel
+}
+
+func newChannelPool(size int, open func() (*confirmChannel, error)) *channelPool {
+	return &channelPool{
+		open: open,
+		size: size,
+		idle: make(chan *confirmChannel, size),
+		sem:  make(chan struct{}, size),
+	}
+}
+
+func (p *channelPool) get(ctx context.Context) (*confirmChannel, error) {
+	if p.size == 0 {
+		return p.open()
+	}
+
+	for {
+		select {
+		case cc := <-p.idle:
+			// Channels die with their connection; drop them after a reconnect
+			if cc.ch.IsClosed() {
+				<-p.sem
+				continue
+			}
+			return cc, nil
+		default:
+		}
+
+		select {
+		case cc := <-p.idle:
+			if cc.ch.IsClosed() {
+				<-p.sem
+				continue
+			}
+			return cc, nil
+		case p.sem <- struct{}{}:
+			cc, err := p.open()
+			if err != nil {
+				<-p.sem
+				return nil, err
+			}
+			return cc, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// put returns the channel after a publish, or closes it when err left it unusable
+func (p *channelPool) put(cc *confirmChannel, err error) {
+	if p.size == 0 {
+		cc.close()
+		return
+	}
+	if !reusable(err) || cc.ch.IsClosed() {
+		cc.close()
+		<-p.sem
+		return
+	}
+	p.idle <- cc
+}
+
+// drain closes idle channels, used on shutdown
+func (p *channelPool) drain() {
+	for {
+		select {
+		case cc := <-p.idle:
+			cc.close()
+			<-p.sem
+		default:
+			return
+		}
+	}
+}
+
+// reusable reports whether a channel may publish again after err. Nacks and
+// returns are per message; anything else may have broken the channel.
+func reusable(err error) bool {
+	return err == nil || errors.Is(err, ErrNacked) || errors.Is(err, ErrUnroutable)
+}
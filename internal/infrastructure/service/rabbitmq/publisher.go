@@ -0,0 +1,118 @@
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const defaultConfirmTimeout = 5 * time.Second
+
+// Publish errors are the messaging port's, so callers need not import this package
+var (
+	ErrNacked         = messaging.ErrNacked
+	ErrUnroutable     = messaging.ErrUnroutable
+	ErrConfirmTimeout = messaging.ErrConfirmTimeout
+)
+
+// PublishError describes a publish the broker did not accept. Err is one of
+// ErrNacked, ErrUnroutable or ErrConfirmTimeout.
+type PublishError struct {
+	Exchange   string
+	RoutingKey string
+	MessageID  string
+	ReplyCode  uint16 // set for ErrUnroutable
+	ReplyText  string
+	Err        error
+}
+
+func (e *PublishError) Error() string {
+	if e.ReplyText != "" {
+		return fmt.Sprintf("%v: exchange=%s routingKey=%s messageId=%s (%d %s)", e.Err, e.Exchange, e.RoutingKey, e.MessageID, e.ReplyCode, e.ReplyText)
+	}
+	return fmt.Sprintf("%v: exchange=%s routingKey=%s messageId=%s", e.Err, e.Exchange, e.RoutingKey, e.MessageID)
+}
+
+func (e *PublishError) Unwrap() error {
+	return e.Err
+}
+
+// confirmChannel is a channel in confirm mode used by one publisher at a time.
+// Exclusive use is what makes returns attributable: the broker sends
+// basic.return before basic.ack on the same channel and the client dispatches
+// frames in order, so a return is buffered by the time its ack is seen.
+type confirmChannel struct {
+	ch      *amqp.Channel
+	returns chan amqp.Return
+}
+
+func newConfirmChannel(ch *amqp.Channel) (*confirmChannel, error) {
+	if err := ch.Confirm(false); err != nil {
+		_ = ch.Close()
+		return nil, err
+	}
+	return &confirmChannel{
+		ch:      ch,
+		returns: ch.NotifyReturn(make(chan amqp.Return, 1)),
+	}, nil
+}
+
+// publish sends msg and waits for the broker's confirm. A non-nil error other
+// than a nack or unroutable return leaves the channel in an unknown state and
+// the caller must discard it.
+func (c *confirmChannel) publish(exchange, routingKey string, mandatory bool, msg amqp.Publishing, timeout time.Duration) error {
+	c.drainReturns()
+
+	if msg.MessageId == "" {
+		msg.MessageId = uuid.New().String()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	dc, err := c.ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, mandatory, false, msg)
+	if err != nil {
+		return err
+	}
+
+	acked, err := dc.WaitContext(ctx)
+	if err != nil {
+		return &PublishError{Exchange: exchange, RoutingKey: routingKey, MessageID: msg.MessageId, Err: ErrConfirmTimeout}
+	}
+	if !acked {
+		return &PublishError{Exchange: exchange, RoutingKey: routingKey, MessageID: msg.MessageId, Err: ErrNacked}
+	}
+
+	select {
+	case ret := <-c.returns:
+		return &PublishError{
+			Exchange:   exchange,
+			RoutingKey: routingKey,
+			MessageID:  ret.MessageId,
+			ReplyCode:  ret.ReplyCode,
+			ReplyText:  ret.ReplyText,
+			Err:        ErrUnroutable,
+		}
+	default:
+		return nil
+	}
+}
+
+// drainReturns drops returns left over from a publish that timed out
+func (c *confirmChannel) drainReturns() {
+	for {
+		select {
+		case <-c.returns:
+		default:
+			return
+		}
+	}
+}
+
+func (c *confirmChannel) close() {
+	_ = c.ch.Close()
+}
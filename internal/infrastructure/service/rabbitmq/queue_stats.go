@@ -0,0 +1,28 @@
+package rabbitmq
+
+import (
+	"context"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+// QueueStats reads the ready count and consumer count of queue with a passive
+// declare. A missing queue fails the declare and closes its channel, so every
+// call uses a channel of its own.
+func (b *Broker) QueueStats(ctx context.Context, queue string) (messaging.QueueStats, error) {
+	if err := ctx.Err(); err != nil {
+		return messaging.QueueStats{}, err
+	}
+
+	ch, err := b.r.Channel()
+	if err != nil {
+		return messaging.QueueStats{}, err
+	}
+	defer ch.Close()
+
+	q, err := ch.QueueDeclarePassive(queue, true, false, false, false, nil)
+	if err != nil {
+		return messaging.QueueStats{}, err
+	}
+	return messaging.QueueStats{Messages: q.Messages, Consumers: q.Consumers}, nil
+}
@@ -1,73 +1,317 @@
 package rabbitmq
 
 import (
+	"context"
 	"errors"
+	"log"
+	"math/rand"
+	"sync"
 	"time"
 
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+const (
+	defaultMinBackoff      = 500 * time.Millisecond
+	defaultMaxBackoff      = 30 * time.Second
+	defaultPublishPoolSize = 8
+)
+
+// Options tunes the service. Zero values fall back to the defaults, except
+// PublishPoolSize where a negative value disables pooling.
+type Options struct {
+	PublishPoolSize int
+	ConfirmTimeout  time.Duration
+	MinBackoff      time.Duration
+	MaxBackoff      time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.PublishPoolSize == 0 {
+		o.PublishPoolSize = defaultPublishPoolSize
+	}
+	if o.PublishPoolSize < 0 {
+		o.PublishPoolSize = 0
+	}
+	if o.ConfirmTimeout <= 0 {
+		o.ConfirmTimeout = defaultConfirmTimeout
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = defaultMinBackoff
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = defaultMaxBackoff
+	}
+	return o
+}
+
+var (
+	ErrNotConnected = messaging.ErrNotConnected
+	ErrClosed       = errors.New("rabbitmq: service closed")
+)
+
 type RabbitMQService struct {
-	url  string
-	conn *amqp.Connection
+	url string
+
+	mu      sync.RWMutex
+	conn    *amqp.Connection
+	ready   bool
+	readyCh chan struct{} // closed while connected, replaced on disconnect
+	hooks   []func() error
+
+	opts Options
+	pool *channelPool
+
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
+// New dials the broker and keeps the connection alive: when the broker drops
+// it, the service reconnects with exponential backoff and re-runs the hooks
+// registered with OnReconnect before reporting ready again.
 func New(url string) (*RabbitMQService, error) {
+	return NewWithOptions(url, Options{})
+}
+
+func NewWithOptions(url string, opts Options) (*RabbitMQService, error) {
 	conn, err := amqp.Dial(url)
 	if err != nil {
 		return nil, err
 	}
-	return &RabbitMQService{
-		url:  url,
-		conn: conn,
-	}, nil
+
+	r := &RabbitMQService{
+		url:     url,
+		conn:    conn,
+		ready:   true,
+		readyCh: make(chan struct{}),
+		opts:    opts.withDefaults(),
+		done:    make(chan struct{}),
+	}
+	close(r.readyCh)
+	r.pool = newChannelPool(r.opts.PublishPoolSize, r.openConfirmChannel)
+
+	go r.watch(conn)
+	return r, nil
 }
 
 // Channel returns a new channel from the connection
 func (r *RabbitMQService) Channel() (*amqp.Channel, error) {
-	if r.conn == nil {
-		return nil, errors.New("rabbitmq: connection is nil")
+	r.mu.RLock()
+	conn, ready := r.conn, r.ready
+	r.mu.RUnlock()
+
+	if conn == nil || !ready {
+		return nil, ErrNotConnected
+	}
+	return conn.Channel()
+}
+
+// setupChannel opens a channel whether or not the service is ready. Only the
+// OnReconnect hooks use it, to declare topology before ready is reported.
+func (r *RabbitMQService) setupChannel() (*amqp.Channel, error) {
+	r.mu.RLock()
+	conn := r.conn
+	r.mu.RUnlock()
+
+	if conn == nil {
+		return nil, ErrNotConnected
+	}
+	return conn.Channel()
+}
+
+// IsReady reports whether the connection is up and topology has been declared
+func (r *RabbitMQService) IsReady() bool {
+	if r == nil {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ready
+}
+
+// WaitReady blocks until the service is ready, ctx is done or the service is closed
+func (r *RabbitMQService) WaitReady(ctx context.Context) error {
+	r.mu.RLock()
+	readyCh := r.readyCh
+	r.mu.RUnlock()
+
+	select {
+	case <-readyCh:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-r.done:
+		return ErrClosed
 	}
-	return r.conn.Channel()
 }
 
+// OnReconnect registers fn to run after every reconnect, before the service
+// is marked ready. Used to re-declare topology; Channel fails until every hook
+// has passed, so hooks open channels with setupChannel.
+func (r *RabbitMQService) OnReconnect(fn func() error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, fn)
+}
+
+// Publish sends a persistent command message and waits for the broker to
+// confirm it. The message is mandatory: if no queue is bound for the routing
+// key the broker returns it and Publish fails with ErrUnroutable. The exchange
+// must already be declared (see DeclareTopology and DeclareExchange).
 func (r *RabbitMQService) Publish(exchange, routingKey string, body []byte) error {
-	ch, err := r.Channel()
+	return r.publish(exchange, routingKey, true, body)
+}
+
+// PublishTo is meant for events: it waits for the confirm but does not fail
+// when nobody is subscribed.
+func (r *RabbitMQService) PublishTo(exchange, routingKey string, body []byte) error {
+	return r.publish(exchange, routingKey, false, body)
+}
+
+func (r *RabbitMQService) publish(exchange, routingKey string, mandatory bool, body []byte) error {
+	return r.publishMsg(exchange, routingKey, mandatory, amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		Timestamp:    time.Now(),
+		Body:         body,
+	})
+}
+
+func (r *RabbitMQService) publishMsg(exchange, routingKey string, mandatory bool, msg amqp.Publishing) error {
+	ctx, cancel := context.WithTimeout(context.Background(), r.opts.ConfirmTimeout)
+	defer cancel()
+
+	ch, err := r.pool.get(ctx)
 	if err != nil {
 		return err
 	}
-	// Close channel after publish
-	defer ch.Close()
-
-	// Make sure exchange exists (idempotent)
-	if err := ch.ExchangeDeclare(
-		exchange,
-		"direct",
-		true,  // durable
-		false, // auto-deleted
-		false,
-		false,
-		nil,
-	); err != nil {
-		return err
-	}
 
-	return ch.Publish(
-		exchange,
-		routingKey,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent,
-			Timestamp:    time.Now(),
-			Body:         body,
-		},
-	)
+	err = ch.publish(exchange, routingKey, mandatory, msg, r.opts.ConfirmTimeout)
+	r.pool.put(ch, err)
+	return err
+}
+
+func (r *RabbitMQService) openConfirmChannel() (*confirmChannel, error) {
+	ch, err := r.Channel()
+	if err != nil {
+		return nil, err
+	}
+	return newConfirmChannel(ch)
 }
 
 func (r *RabbitMQService) Close() {
-	if r.conn != nil {
-		_ = r.conn.Close()
+	r.closeOnce.Do(func() {
+		close(r.done)
+
+		r.mu.Lock()
+		conn := r.conn
+		r.ready = false
+		r.mu.Unlock()
+
+		r.pool.drain()
+		if conn != nil {
+			_ = conn.Close()
+		}
+	})
+}
+
+// watch reconnects whenever the broker closes the connection
+func (r *RabbitMQService) watch(conn *amqp.Connection) {
+	for {
+		closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+
+		select {
+		case <-r.done:
+			return
+		case err, ok := <-closed:
+			if !ok {
+				// Closed by us
+				return
+			}
+			log.Printf("rabbitmq: connection lost: %v", err)
+		}
+
+		r.mu.Lock()
+		r.ready = false
+		r.readyCh = make(chan struct{})
+		r.mu.Unlock()
+
+		conn = r.reconnect()
+		if conn == nil {
+			return
+		}
+	}
+}
+
+// reconnect dials until it succeeds and every hook passes. It returns nil once
+// the service is closed.
+func (r *RabbitMQService) reconnect() *amqp.Connection {
+	for attempt := 0; ; attempt++ {
+		select {
+		case <-r.done:
+			return nil
+		case <-time.After(r.backoff(attempt)):
+		}
+
+		conn, err := amqp.Dial(r.url)
+		if err != nil {
+			log.Printf("rabbitmq: reconnect attempt %d failed: %v", attempt+1, err)
+			continue
+		}
+
+		r.mu.Lock()
+		select {
+		case <-r.done:
+			r.mu.Unlock()
+			_ = conn.Close()
+			return nil
+		default:
+		}
+		r.conn = conn
+		hooks := append([]func() error(nil), r.hooks...)
+		r.mu.Unlock()
+
+		if err := runHooks(hooks); err != nil {
+			log.Printf("rabbitmq: re-declare after reconnect failed: %v", err)
+			_ = conn.Close()
+			continue
+		}
+
+		r.mu.Lock()
+		select {
+		case <-r.done:
+			// Closed while the hooks ran
+			r.mu.Unlock()
+			return nil
+		default:
+		}
+		r.ready = true
+		close(r.readyCh)
+		r.mu.Unlock()
+
+		log.Printf("rabbitmq: reconnected after %d attempt(s)", attempt+1)
+		return conn
+	}
+}
+
+// backoff doubles per attempt up to maxBackoff, with up to 20% jitter
+func (r *RabbitMQService) backoff(attempt int) time.Duration {
+	d := r.opts.MinBackoff
+	for i := 0; i < attempt && d < r.opts.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > r.opts.MaxBackoff {
+		d = r.opts.MaxBackoff
+	}
+	return d + time.Duration(rand.Int63n(int64(d)/5+1))
+}
+
+func runHooks(hooks []func() error) error {
+	for _, fn := range hooks {
+		if err := fn(); err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -0,0 +1,103 @@
+package rabbitmq
+
+import (
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// retryCountHeader is set on every republish. The broker may not carry x-death
+// entries over a republish, so attempts are also tracked explicitly.
+const retryCountHeader = "x-retry-count"
+
+// Attempts returns how many retry tiers the delivery has already been through,
+// read from the x-death entries of expired retry queues.
+func Attempts(d amqp.Delivery, cfg TopologyConfig) int {
+	fromDeath := 0
+	if deaths, ok := d.Headers["x-death"].([]interface{}); ok {
+		for _, raw := range deaths {
+			death, ok := raw.(amqp.Table)
+			if !ok {
+				continue
+			}
+			queue, _ := death["queue"].(string)
+			reason, _ := death["reason"].(string)
+			if reason != "expired" || !strings.HasPrefix(queue, cfg.Queue+".retry.") {
+				continue
+			}
+			fromDeath += int(toInt64(death["count"]))
+		}
+	}
+
+	fromHeader := int(toInt64(d.Headers[retryCountHeader]))
+	if fromHeader > fromDeath {
+		return fromHeader
+	}
+	return fromDeath
+}
+
+// RetryOrPark handles a delivery that failed with a transient error. It sends
+// the message to the next retry tier and acks the original, or, once
+// MaxAttempts is reached, rejects it so the DLX parks it. Without retry
+// configuration it falls back to requeueing.
+func (r *RabbitMQService) RetryOrPark(d amqp.Delivery, cfg TopologyConfig) (parked bool, err error) {
+	if !cfg.retryEnabled() {
+		return false, d.Nack(false, true)
+	}
+
+	attempts := Attempts(d, cfg)
+	if cfg.Retry.MaxAttempts > 0 && attempts >= cfg.Retry.MaxAttempts {
+		return true, d.Reject(false)
+	}
+
+	tier := attempts
+	if tier >= len(cfg.Retry.Delays) {
+		tier = len(cfg.Retry.Delays) - 1
+	}
+
+	headers := amqp.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers[retryCountHeader] = int64(attempts + 1)
+
+	if err := r.publishMsg(cfg.Retry.Exchange, cfg.RetryQueue(tier), true, amqp.Publishing{
+		Headers:       headers,
+		ContentType:   d.ContentType,
+		DeliveryMode:  amqp.Persistent,
+		MessageId:     d.MessageId,
+		CorrelationId: d.CorrelationId,
+		Timestamp:     d.Timestamp,
+		Type:          d.Type,
+		Body:          d.Body,
+	}); err != nil {
+		// Could not stage the retry; fall back to an immediate requeue
+		_ = d.Nack(false, true)
+		return false, err
+	}
+	return false, d.Ack(false)
+}
+
+func toInt64(v interface{}) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int32:
+		return int64(n)
+	case int:
+		return int64(n)
+	case int16:
+		return int64(n)
+	case int8:
+		return int64(n)
+	case uint8:
+		return int64(n)
+	case uint16:
+		return int64(n)
+	case uint32:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
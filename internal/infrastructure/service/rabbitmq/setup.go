@@ -1,69 +1,108 @@
 package rabbitmq
 
 import (
+	"fmt"
 	"log"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
+// TopologyConfig describes one consumed queue: the exchange and routing key it
+// is fed by, where it parks and how it retries. Build it from the declared
+// topology with Topology.QueueConfig.
 type TopologyConfig struct {
 	Exchange   string
 	ExchangeTy string
 	Queue      string
 	RoutingKey string
-	// Optional: DLX string
-	DLX string
+	// Optional: dead-letter exchange for rejected messages, and the parking
+	// queue bound to it where messages end up once retries are exhausted
+	DLX          string
+	ParkingQueue string
+	// Optional: tiered retry through TTL queues
+	Retry RetryConfig
 }
 
-func DeclareTopology(r *RabbitMQService, cfg TopologyConfig) error {
-	ch, err := r.Channel()
-	if err != nil {
-		return err
-	}
-	defer ch.Close()
+// RetryConfig describes the retry tiers. Each delay gets its own queue with
+// that TTL whose expired messages are dead-lettered back to the main exchange.
+// Attempts beyond the last tier reuse the last delay.
+type RetryConfig struct {
+	Exchange    string
+	Delays      []time.Duration
+	MaxAttempts int
+}
+
+func (c TopologyConfig) retryEnabled() bool {
+	return c.Retry.Exchange != "" && len(c.Retry.Delays) > 0
+}
+
+// RetryQueue is the name of the queue for retry tier i, e.g. topup_queue.retry.30s
+func (c TopologyConfig) RetryQueue(i int) string {
+	return fmt.Sprintf("%s.retry.%s", c.Queue, c.Retry.Delays[i])
+}
 
-	// Declare exchange
-	if err := ch.ExchangeDeclare(
-		cfg.Exchange,
-		cfg.ExchangeTy,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	); err != nil {
+// DeclareTopology declares every exchange, queue and binding of t now and
+// again after every reconnect. Declaring is idempotent: objects that already
+// exist with the same settings are left alone. An object that exists with
+// different settings fails with PRECONDITION_FAILED; use DiffTopology to find
+// it before applying.
+func DeclareTopology(r *RabbitMQService, t *Topology) error {
+	if err := t.Validate(); err != nil {
 		return err
 	}
+	expanded := t.Expand()
 
-	// Declare queue (optionally set DLX)
-	table := amqp.Table{}
-	if cfg.DLX != "" {
-		table["x-dead-letter-exchange"] = cfg.DLX
+	if err := declareTopology(r, expanded); err != nil {
+		return err
 	}
+	r.OnReconnect(func() error {
+		return declareTopology(r, expanded)
+	})
+	return nil
+}
 
-	_, err = ch.QueueDeclare(
-		cfg.Queue,
-		true,
-		false,
-		false,
-		false,
-		table,
-	)
+func declareTopology(r *RabbitMQService, t *Topology) error {
+	ch, err := r.setupChannel()
 	if err != nil {
 		return err
 	}
+	defer ch.Close()
 
-	// Bind queue to exchange
-	if err := ch.QueueBind(
-		cfg.Queue,
-		cfg.RoutingKey,
-		cfg.Exchange,
-		false,
-		nil,
-	); err != nil {
-		return err
+	for _, e := range t.Exchanges {
+		if err := ch.ExchangeDeclare(e.Name, e.Type, e.durable(), e.AutoDelete, e.Internal, false, amqp.Table(e.Arguments)); err != nil {
+			return fmt.Errorf("declare exchange %s: %w", e.Name, err)
+		}
+	}
+	for _, q := range t.Queues {
+		if _, err := ch.QueueDeclare(q.Name, q.durable(), q.AutoDelete, false, false, q.args()); err != nil {
+			return fmt.Errorf("declare queue %s: %w", q.Name, err)
+		}
+	}
+	for _, b := range t.Bindings {
+		if err := ch.QueueBind(b.Queue, b.RoutingKey, b.Exchange, false, amqp.Table(b.Arguments)); err != nil {
+			return fmt.Errorf("bind queue %s to %s (%s): %w", b.Queue, b.Exchange, b.RoutingKey, err)
+		}
 	}
 
-	log.Printf("rabbitmq: declared exchange=%s queue=%s routingKey=%s", cfg.Exchange, cfg.Queue, cfg.RoutingKey)
+	log.Printf("rabbitmq: declared %d exchanges, %d queues, %d bindings", len(t.Exchanges), len(t.Queues), len(t.Bindings))
+	return nil
+}
+
+// DeclareExchange declares a durable exchange now and after every reconnect
+func DeclareExchange(r *RabbitMQService, name, kind string) error {
+	declare := func() error {
+		ch, err := r.setupChannel()
+		if err != nil {
+			return err
+		}
+		defer ch.Close()
+		return ch.ExchangeDeclare(name, kind, true, false, false, false, nil)
+	}
+
+	if err := declare(); err != nil {
+		return err
+	}
+	r.OnReconnect(declare)
 	return nil
 }
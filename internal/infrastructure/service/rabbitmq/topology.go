@@ -0,0 +1,280 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.yaml.in/yaml/v3"
+)
+
+var ErrInvalidTopology = errors.New("rabbitmq: invalid topology")
+
+var exchangeKinds = map[string]bool{"direct": true, "fanout": true, "topic": true, "headers": true}
+
+// Topology is the declarative broker layout, usually loaded from
+// config/topology.yaml. Retry blocks on queues are expanded into their
+// exchange, TTL queues and bindings by Expand.
+type Topology struct {
+	Exchanges []ExchangeSpec `yaml:"exchanges"`
+	Queues    []QueueSpec    `yaml:"queues"`
+	Bindings  []BindingSpec  `yaml:"bindings"`
+}
+
+type ExchangeSpec struct {
+	Name       string                 `yaml:"name"`
+	Type       string                 `yaml:"type"`
+	Durable    *bool                  `yaml:"durable"` // default true
+	AutoDelete bool                   `yaml:"autoDelete"`
+	Internal   bool                   `yaml:"internal"`
+	Arguments  map[string]interface{} `yaml:"arguments"`
+}
+
+type QueueSpec struct {
+	Name                 string                 `yaml:"name"`
+	Durable              *bool                  `yaml:"durable"` // default true
+	AutoDelete           bool                   `yaml:"autoDelete"`
+	DeadLetterExchange   string                 `yaml:"deadLetterExchange"`
+	DeadLetterRoutingKey string                 `yaml:"deadLetterRoutingKey"`
+	MessageTTL           time.Duration          `yaml:"messageTTL"`
+	MaxPriority          int                    `yaml:"maxPriority"`
+	Arguments            map[string]interface{} `yaml:"arguments"`
+	Retry                *RetrySpec             `yaml:"retry"`
+}
+
+// RetrySpec gives a queue tiered retries (see RetryConfig). Expired retries
+// return through the queue's first binding.
+type RetrySpec struct {
+	Exchange    string          `yaml:"exchange"`
+	Delays      []time.Duration `yaml:"delays"`
+	MaxAttempts int             `yaml:"maxAttempts"`
+}
+
+type BindingSpec struct {
+	Exchange   string                 `yaml:"exchange"`
+	Queue      string                 `yaml:"queue"`
+	RoutingKey string                 `yaml:"routingKey"`
+	Arguments  map[string]interface{} `yaml:"arguments"`
+}
+
+// LoadTopology reads and validates a topology file. Unknown keys are errors so
+// typos do not silently drop settings.
+func LoadTopology(path string) (*Topology, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTopology(raw)
+}
+
+func ParseTopology(raw []byte) (*Topology, error) {
+	var t Topology
+	dec := yaml.NewDecoder(bytes.NewReader(raw))
+	dec.KnownFields(true)
+	if err := dec.Decode(&t); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTopology, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Validate reports every problem at once, each wrapped in ErrInvalidTopology
+func (t *Topology) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidTopology, fmt.Sprintf(format, args...)))
+	}
+
+	exchanges := map[string]bool{}
+	for i, e := range t.Exchanges {
+		switch {
+		case e.Name == "":
+			fail("exchanges[%d]: name is required", i)
+		case exchanges[e.Name]:
+			fail("exchange %q declared twice", e.Name)
+		}
+		if !exchangeKinds[e.Type] {
+			fail("exchange %q: type must be direct, fanout, topic or headers", e.Name)
+		}
+		if err := amqp.Table(e.Arguments).Validate(); err != nil {
+			fail("exchange %q: arguments: %v", e.Name, err)
+		}
+		exchanges[e.Name] = true
+	}
+
+	queues := map[string]QueueSpec{}
+	for i, q := range t.Queues {
+		switch {
+		case q.Name == "":
+			fail("queues[%d]: name is required", i)
+		case queues[q.Name].Name != "":
+			fail("queue %q declared twice", q.Name)
+		}
+		if q.DeadLetterExchange != "" && !exchanges[q.DeadLetterExchange] {
+			fail("queue %q: dead-letter exchange %q is not declared", q.Name, q.DeadLetterExchange)
+		}
+		if q.DeadLetterRoutingKey != "" && q.DeadLetterExchange == "" {
+			fail("queue %q: deadLetterRoutingKey needs deadLetterExchange", q.Name)
+		}
+		if q.MessageTTL < 0 || (q.MessageTTL > 0 && q.MessageTTL < time.Millisecond) {
+			fail("queue %q: messageTTL must be at least 1ms", q.Name)
+		}
+		if q.MaxPriority < 0 || q.MaxPriority > 255 {
+			fail("queue %q: maxPriority must be between 0 and 255", q.Name)
+		}
+		if err := amqp.Table(q.Arguments).Validate(); err != nil {
+			fail("queue %q: arguments: %v", q.Name, err)
+		}
+		if r := q.Retry; r != nil {
+			if r.Exchange == "" {
+				fail("queue %q: retry.exchange is required", q.Name)
+			}
+			if len(r.Delays) == 0 {
+				fail("queue %q: retry.delays needs at least one delay", q.Name)
+			}
+			for _, d := range r.Delays {
+				if d < time.Millisecond {
+					fail("queue %q: retry delay %s must be at least 1ms", q.Name, d)
+				}
+			}
+			if r.MaxAttempts < 0 {
+				fail("queue %q: retry.maxAttempts cannot be negative", q.Name)
+			}
+			if q.DeadLetterExchange == "" {
+				fail("queue %q: retry needs a deadLetterExchange to park exhausted messages", q.Name)
+			}
+		}
+		queues[q.Name] = q
+	}
+
+	bound := map[string]bool{}
+	for i, b := range t.Bindings {
+		if !exchanges[b.Exchange] {
+			fail("bindings[%d]: exchange %q is not declared", i, b.Exchange)
+		}
+		if _, ok := queues[b.Queue]; !ok {
+			fail("bindings[%d]: queue %q is not declared", i, b.Queue)
+		}
+		if err := amqp.Table(b.Arguments).Validate(); err != nil {
+			fail("bindings[%d]: arguments: %v", i, err)
+		}
+		bound[b.Queue] = true
+	}
+	for _, q := range t.Queues {
+		if q.Retry != nil && !bound[q.Name] {
+			fail("queue %q: retry needs a binding to return expired messages through", q.Name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Expand returns the topology with retry blocks turned into plain exchanges,
+// queues and bindings
+func (t *Topology) Expand() *Topology {
+	out := &Topology{
+		Exchanges: append([]ExchangeSpec(nil), t.Exchanges...),
+		Bindings:  append([]BindingSpec(nil), t.Bindings...),
+	}
+	declared := map[string]bool{}
+	for _, e := range t.Exchanges {
+		declared[e.Name] = true
+	}
+
+	for _, q := range t.Queues {
+		retry := q.Retry
+		q.Retry = nil
+		out.Queues = append(out.Queues, q)
+		if retry == nil {
+			continue
+		}
+
+		if !declared[retry.Exchange] {
+			out.Exchanges = append(out.Exchanges, ExchangeSpec{Name: retry.Exchange, Type: "direct"})
+			declared[retry.Exchange] = true
+		}
+
+		cfg, _ := t.QueueConfig(q.Name)
+		for i, delay := range retry.Delays {
+			name := cfg.RetryQueue(i)
+			// Expired messages go back to the main queue with the original routing key
+			out.Queues = append(out.Queues, QueueSpec{
+				Name:                 name,
+				DeadLetterExchange:   cfg.Exchange,
+				DeadLetterRoutingKey: cfg.RoutingKey,
+				MessageTTL:           delay,
+			})
+			out.Bindings = append(out.Bindings, BindingSpec{Exchange: retry.Exchange, Queue: name, RoutingKey: name})
+		}
+	}
+	return out
+}
+
+// QueueConfig is the consumer view of one queue: where it is published, where
+// it parks and how it retries
+func (t *Topology) QueueConfig(queue string) (TopologyConfig, error) {
+	for _, q := range t.Queues {
+		if q.Name != queue {
+			continue
+		}
+
+		cfg := TopologyConfig{Queue: q.Name, DLX: q.DeadLetterExchange}
+		for _, b := range t.Bindings {
+			if b.Queue == q.Name && cfg.Exchange == "" {
+				cfg.Exchange, cfg.RoutingKey = b.Exchange, b.RoutingKey
+			}
+			// The parking queue is whatever the DLX routes this queue's rejects to
+			if q.DeadLetterExchange != "" && b.Exchange == q.DeadLetterExchange && b.RoutingKey == q.DeadLetterRoutingKey && cfg.ParkingQueue == "" {
+				cfg.ParkingQueue = b.Queue
+			}
+		}
+		for _, e := range t.Exchanges {
+			if e.Name == cfg.Exchange {
+				cfg.ExchangeTy = e.Type
+			}
+		}
+		if q.Retry != nil {
+			cfg.Retry = RetryConfig{
+				Exchange:    q.Retry.Exchange,
+				Delays:      q.Retry.Delays,
+				MaxAttempts: q.Retry.MaxAttempts,
+			}
+		}
+		return cfg, nil
+	}
+	return TopologyConfig{}, fmt.Errorf("%w: queue %q is not declared", ErrInvalidTopology, queue)
+}
+
+func (e ExchangeSpec) durable() bool {
+	return e.Durable == nil || *e.Durable
+}
+
+func (q QueueSpec) durable() bool {
+	return q.Durable == nil || *q.Durable
+}
+
+// args merges the typed settings into the raw arguments as the broker sees them
+func (q QueueSpec) args() amqp.Table {
+	table := amqp.Table{}
+	for k, v := range q.Arguments {
+		table[k] = v
+	}
+	if q.DeadLetterExchange != "" {
+		table["x-dead-letter-exchange"] = q.DeadLetterExchange
+	}
+	if q.DeadLetterRoutingKey != "" {
+		table["x-dead-letter-routing-key"] = q.DeadLetterRoutingKey
+	}
+	if q.MessageTTL > 0 {
+		table["x-message-ttl"] = q.MessageTTL.Milliseconds()
+	}
+	if q.MaxPriority > 0 {
+		table["x-max-priority"] = int64(q.MaxPriority)
+	}
+	return table
+}
@@ -0,0 +1,221 @@
+package rabbitmq
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const topologyFile = "../../../../config/topology.yaml"
+
+func loadTopology(t *testing.T) *Topology {
+	t.Helper()
+	topology, err := LoadTopology(topologyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return topology
+}
+
+func TestLoadTopologyFile(t *testing.T) {
+	topology := loadTopology(t)
+
+	tests := []struct {
+		queue string
+		want  TopologyConfig
+	}{
+		{"topup_queue", TopologyConfig{
+			Exchange: "topup.exchange", ExchangeTy: "direct", Queue: "topup_queue", RoutingKey: "topup.created",
+			DLX: "topup.dlx", ParkingQueue: "topup_queue.parking",
+			Retry: RetryConfig{Exchange: "topup.retry", Delays: []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute}, MaxAttempts: 5},
+		}},
+		{"topup_queue.priority", TopologyConfig{
+			Exchange: "topup.exchange", ExchangeTy: "direct", Queue: "topup_queue.priority", RoutingKey: "topup.created.priority",
+			DLX: "topup.dlx", ParkingQueue: "topup_queue.parking",
+			Retry: RetryConfig{Exchange: "topup.retry", Delays: []time.Duration{5 * time.Second, 30 * time.Second, 2 * time.Minute}, MaxAttempts: 5},
+		}},
+		{"rpc_queue", TopologyConfig{Exchange: "rpc.exchange", ExchangeTy: "direct", Queue: "rpc_queue", RoutingKey: "rpc.request"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.queue, func(t *testing.T) {
+			got, err := topology.QueueConfig(tt.queue)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("QueueConfig =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := topology.QueueConfig("nope"); !errors.Is(err, ErrInvalidTopology) {
+		t.Errorf("unknown queue: err = %v, want ErrInvalidTopology", err)
+	}
+}
+
+func TestExpandRetryTiers(t *testing.T) {
+	expanded := loadTopology(t).Expand()
+
+	exchanges := map[string]ExchangeSpec{}
+	for _, e := range expanded.Exchanges {
+		if _, dup := exchanges[e.Name]; dup {
+			t.Errorf("exchange %q declared twice", e.Name)
+		}
+		exchanges[e.Name] = e
+	}
+	if e, ok := exchanges["topup.retry"]; !ok || e.Type != "direct" {
+		t.Errorf("retry exchange = %+v, want a direct topup.retry", e)
+	}
+
+	queues := map[string]QueueSpec{}
+	for _, q := range expanded.Queues {
+		if q.Retry != nil {
+			t.Errorf("queue %q still has a retry block", q.Name)
+		}
+		queues[q.Name] = q
+	}
+	bindings := map[string]BindingSpec{}
+	for _, b := range expanded.Bindings {
+		bindings[b.Queue+"|"+b.RoutingKey] = b
+	}
+
+	lanes := map[string]string{"topup_queue": "topup.created", "topup_queue.priority": "topup.created.priority"}
+	for queue, key := range lanes {
+		for _, tier := range []struct {
+			suffix string
+			ttl    time.Duration
+		}{{"5s", 5 * time.Second}, {"30s", 30 * time.Second}, {"2m0s", 2 * time.Minute}} {
+			name := queue + ".retry." + tier.suffix
+			q, ok := queues[name]
+			if !ok {
+				t.Errorf("retry queue %q missing", name)
+				continue
+			}
+			// Expired retries go back to their own lane, not the other one
+			if q.DeadLetterExchange != "topup.exchange" || q.DeadLetterRoutingKey != key || q.MessageTTL != tier.ttl {
+				t.Errorf("%s = dlx %q key %q ttl %s; want topup.exchange %q %s", name, q.DeadLetterExchange, q.DeadLetterRoutingKey, q.MessageTTL, key, tier.ttl)
+			}
+			if b, ok := bindings[name+"|"+name]; !ok || b.Exchange != "topup.retry" {
+				t.Errorf("%s: binding from topup.retry with its own name missing", name)
+			}
+		}
+	}
+
+	// The parking route stays as written
+	if q := queues["topup_queue"]; q.DeadLetterExchange != "topup.dlx" || q.DeadLetterRoutingKey != "topup_queue.parking" {
+		t.Errorf("topup_queue parks via %q/%q", q.DeadLetterExchange, q.DeadLetterRoutingKey)
+	}
+	if b, ok := bindings["topup_queue.parking|topup_queue.parking"]; !ok || b.Exchange != "topup.dlx" {
+		t.Error("parking binding from topup.dlx missing")
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"unknown key", `
+exchanges:
+  - name: a
+    type: direct
+    durabel: false
+`, "durabel"},
+		{"exchange type", `
+exchanges:
+  - name: a
+    type: fast
+`, `exchange "a": type must be`},
+		{"duplicate exchange", `
+exchanges:
+  - {name: a, type: direct}
+  - {name: a, type: direct}
+`, `exchange "a" declared twice`},
+		{"duplicate queue", `
+queues:
+  - name: q
+  - name: q
+`, `queue "q" declared twice`},
+		{"undeclared dlx", `
+queues:
+  - name: q
+    deadLetterExchange: missing
+`, `dead-letter exchange "missing" is not declared`},
+		{"dl routing key without dlx", `
+queues:
+  - name: q
+    deadLetterRoutingKey: parked
+`, "deadLetterRoutingKey needs deadLetterExchange"},
+		{"ttl below a millisecond", `
+queues:
+  - name: q
+    messageTTL: 10us
+`, "messageTTL must be at least 1ms"},
+		{"priority out of range", `
+queues:
+  - name: q
+    maxPriority: 300
+`, "maxPriority must be between 0 and 255"},
+		{"retry without delays or dlx", `
+exchanges:
+  - {name: a, type: direct}
+queues:
+  - name: q
+    retry:
+      exchange: a.retry
+bindings:
+  - {exchange: a, queue: q, routingKey: q}
+`, "retry.delays needs at least one delay"},
+		{"retry without dlx", `
+exchanges:
+  - {name: a, type: direct}
+queues:
+  - name: q
+    retry:
+      exchange: a.retry
+      delays: [1s]
+bindings:
+  - {exchange: a, queue: q, routingKey: q}
+`, "retry needs a deadLetterExchange"},
+		{"retry without binding", `
+exchanges:
+  - {name: dlx, type: direct}
+queues:
+  - name: q
+    deadLetterExchange: dlx
+    retry:
+      exchange: a.retry
+      delays: [1s]
+`, "retry needs a binding"},
+		{"binding to undeclared objects", `
+bindings:
+  - {exchange: a, queue: q, routingKey: k}
+`, `exchange "a" is not declared`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTopology([]byte(tt.yaml))
+			if !errors.Is(err, ErrInvalidTopology) {
+				t.Fatalf("err = %v, want ErrInvalidTopology", err)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	_, err := ParseTopology([]byte(`
+exchanges:
+  - {name: a, type: fast}
+queues:
+  - {name: q, maxPriority: -1}
+`))
+	if err == nil || !strings.Contains(err.Error(), "type must be") || !strings.Contains(err.Error(), "maxPriority") {
+		t.Fatalf("err = %v, want both problems", err)
+	}
+}
@@ -2,163 +2,282 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"hash/fnv"
 	"log"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/junicochandra/golang-api-service/internal/domain/repository"
-	"github.com/junicochandra/golang-api-service/internal/infrastructure/service/rabbitmq"
-
-	amqp "github.com/rabbitmq/amqp091-go"
-	"github.com/shopspring/decimal"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
 )
 
-// TopUpMessage according to the payload sent from the usecase
-type TopUpMessage struct {
-	TransactionID string          `json:"transactionId"`
-	AccountNumber string          `json:"accountNumber"`
-	Amount        decimal.Decimal `json:"amount"`
-	Currency      string          `json:"currency"`
-	CreatedAt     time.Time       `json:"createdAt"`
+// resubscribeDelay is the pause before consuming again after the channel drops
+const resubscribeDelay = time.Second
+
+var errDeliveriesClosed = errors.New("deliveries closed")
+
+// ConsumerOptions tunes how many deliveries are processed at once
+type ConsumerOptions struct {
+	// Workers is the number of goroutines handling deliveries (default 1).
+	// Messages for the same account always go to the same worker, so they
+	// are still processed in order.
+	Workers int
+	// Prefetch is the channel QoS, i.e. unacked deliveries held by this
+	// consumer (default: one per worker)
+	Prefetch int
+	// ShardKey picks the ordering key of a delivery; deliveries with the same
+	// key go to the same worker (default: the message ID, i.e. no ordering)
+	ShardKey func(d messaging.Delivery) string
 }
 
-type Consumer struct {
-	rabbit          *rabbitmq.RabbitMQService
-	transactionRepo repository.TransactionRepository
-	accountRepo     repository.AccountRepository
-	queueName       string
-	logger          *log.Logger
+// Lane is one queue consumed by a Consumer. While several lanes have work, a
+// lane gets Weight deliveries for every delivery of a weight-1 lane; an idle
+// lane costs the others nothing.
+type Lane struct {
+	Queue  string
+	Weight int
 }
 
-func NewConsumer(r *rabbitmq.RabbitMQService, trx repository.TransactionRepository, acc repository.AccountRepository, queueName string, logger *log.Logger) *Consumer {
-	return &Consumer{
-		rabbit:          r,
-		transactionRepo: trx,
-		accountRepo:     acc,
-		queueName:       queueName,
-		logger:          logger,
-	}
+// Consumer feeds one or more queues to a handler, usually a Mux, and settles
+// each delivery according to the returned Outcome
+type Consumer struct {
+	broker  messaging.BrokerPort
+	lanes   []Lane
+	handler Handler
+	opts    ConsumerOptions
+	logger  *log.Logger
 }
 
-func (c *Consumer) Start(ctx context.Context) error {
-	ch, err := c.rabbit.Channel()
-	if err != nil {
-		return err
-	}
+func NewConsumer(broker messaging.BrokerPort, queue string, handler Handler, logger *log.Logger) *Consumer {
+	return NewConsumerWithOptions(broker, queue, handler, ConsumerOptions{}, logger)
+}
 
-	// Close channel when context done
-	go func() {
-		<-ctx.Done()
-		_ = ch.Cancel("", false)
-		_ = ch.Close()
-	}()
+func NewConsumerWithOptions(broker messaging.BrokerPort, queue string, handler Handler, opts ConsumerOptions, logger *log.Logger) *Consumer {
+	return NewLaneConsumer(broker, []Lane{{Queue: queue, Weight: 1}}, handler, opts, logger)
+}
 
-	// Set QoS
-	if err := ch.Qos(1, 0, false); err != nil {
-		return err
+// NewLaneConsumer consumes several queues with weighted fairness. Each lane
+// prefetches Prefetch deliveries per unit of weight.
+func NewLaneConsumer(broker messaging.BrokerPort, lanes []Lane, handler Handler, opts ConsumerOptions, logger *log.Logger) *Consumer {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
 	}
-
-	msgs, err := ch.Consume(
-		c.queueName,
-		"",    // consumer tag
-		false, // autoAck
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
-		return err
+	if opts.Prefetch <= 0 {
+		opts.Prefetch = opts.Workers
 	}
+	if opts.ShardKey == nil {
+		opts.ShardKey = func(d messaging.Delivery) string { return d.ID }
+	}
+	lanes = append([]Lane(nil), lanes...)
+	for i := range lanes {
+		if lanes[i].Weight <= 0 {
+			lanes[i].Weight = 1
+		}
+	}
+	return &Consumer{
+		broker:  broker,
+		lanes:   lanes,
+		handler: handler,
+		opts:    opts,
+		logger:  logger,
+	}
+}
 
-	c.logger.Println("worker: waiting for messages...")
-
+// Start consumes until ctx is done. When the channel or connection drops it
+// waits for the broker to come back and subscribes again.
+func (c *Consumer) Start(ctx context.Context) error {
 	for {
+		if err := c.broker.WaitReady(ctx); err != nil {
+			if ctx.Err() != nil {
+				c.logger.Println("worker: context done, stopping")
+				return nil
+			}
+			return err
+		}
+
+		err := c.consume(ctx)
+		if ctx.Err() != nil {
+			c.logger.Println("worker: context done, stopping")
+			return nil
+		}
+		c.logger.Printf("worker: consumer interrupted: %v, resubscribing", err)
+
+		// Avoid a hot loop when the channel fails but the connection is still up
 		select {
 		case <-ctx.Done():
-			c.logger.Println("worker: context done, stopping")
 			return nil
-		case d, ok := <-msgs:
-			if !ok {
-				c.logger.Println("worker: deliveries closed")
-				return nil
-			}
-			if err := c.handleDelivery(d); err != nil {
-				c.logger.Printf("worker: handleDelivery error: %v", err)
-			}
+		case <-time.After(resubscribeDelay):
 		}
 	}
 }
 
-func (c *Consumer) handleDelivery(d amqp.Delivery) error {
-	var m TopUpMessage
-	if err := json.Unmarshal(d.Body, &m); err != nil {
-		c.logger.Printf("worker: invalid message: %v", err)
-		_ = d.Reject(false) // send to DLX if configured
-		return err
+// consume runs one subscription per lane until their deliveries stop.
+// Deliveries are sharded by ShardKey over the worker goroutines; the channels
+// are only closed after every worker has acked or nacked what it was given.
+func (c *Consumer) consume(ctx context.Context) error {
+	subs := make([]messaging.Subscription, 0, len(c.lanes))
+	defer func() {
+		for _, sub := range subs {
+			sub.Close()
+		}
+	}()
+	for _, lane := range c.lanes {
+		// A lane needs Weight times the prefetch to have that many ready
+		sub, err := c.broker.Subscribe(ctx, lane.Queue, messaging.SubscribeOptions{Prefetch: c.opts.Prefetch * lane.Weight})
+		if err != nil {
+			return err
+		}
+		subs = append(subs, sub)
 	}
 
-	// Idempotency check using trx repo
-	trx, err := c.transactionRepo.GetByTransactionID(m.TransactionID)
-	if err != nil {
-		c.logger.Printf("worker: db error GetByID: %v", err)
-		_ = d.Nack(false, true) // requeue
-		return err
-	}
-	if trx == nil {
-		c.logger.Printf("worker: transaction not found: %s", m.TransactionID)
-		_ = d.Reject(false)
-		return errors.New("transaction not found")
+	// Deliveries already received are finished even when ctx is done
+	handlerCtx := context.WithoutCancel(ctx)
+
+	shards := make([]chan messaging.Delivery, c.opts.Workers)
+	var wg sync.WaitGroup
+	for i := range shards {
+		shards[i] = make(chan messaging.Delivery, c.opts.Prefetch)
+		wg.Add(1)
+		go func(in <-chan messaging.Delivery) {
+			defer wg.Done()
+			for d := range in {
+				// Errors are reported by the handler's middleware
+				outcome, _ := c.handler(handlerCtx, d)
+				c.settle(d, outcome)
+			}
+		}(shards[i])
 	}
-	if trx.Status == "completed" || trx.Status == "success" {
-		_ = d.Ack(false)
-		return nil
+
+	c.logger.Printf("worker: waiting for messages on %s (workers=%d prefetch=%d)...", c.describeLanes(), c.opts.Workers, c.opts.Prefetch)
+
+	err := c.merge(ctx, subs, func(d messaging.Delivery) {
+		shards[c.shardFor(d)] <- d
+	})
+
+	// Let the workers settle what they hold before the subscriptions close
+	for _, shard := range shards {
+		close(shard)
 	}
-	if trx.Status == "processing" {
-		_ = d.Nack(false, true)
+	wg.Wait()
+
+	if ctx.Err() != nil {
 		return nil
 	}
+	return err
+}
 
-	// Set processing
-	if err := c.transactionRepo.UpdateStatus(m.TransactionID, "processing"); err != nil {
-		c.logger.Printf("worker: failed set processing: %v", err)
-		_ = d.Nack(false, true)
-		return err
+// merge forwards deliveries from every lane by weighted round robin: each
+// pass takes up to Weight ready deliveries per lane, and when no lane has one
+// ready it waits for whichever delivers first or for ctx. It returns once ctx
+// is done or every lane has closed, or as soon as one closes while ctx is
+// still live.
+func (c *Consumer) merge(ctx context.Context, subs []messaging.Subscription, dispatch func(messaging.Delivery)) error {
+	lanes := make([]<-chan messaging.Delivery, len(subs))
+	for i, sub := range subs {
+		lanes[i] = sub.Deliveries()
 	}
+	open := len(lanes)
 
-	// Get account & update balance atomically via repository method
-	account, err := c.accountRepo.GetByAccountNumber(m.AccountNumber)
-	if err != nil {
-		c.logger.Printf("worker: get account error: %v", err)
-		_ = c.transactionRepo.UpdateStatus(m.TransactionID, "failed_account_error")
-		_ = d.Nack(false, true)
-		return err
-	}
-	if account == nil {
-		c.logger.Printf("worker: account not found: %s", m.AccountNumber)
-		_ = c.transactionRepo.UpdateStatus(m.TransactionID, "failed_account_not_found")
-		_ = d.Ack(false)
-		return errors.New("account not found")
+	laneClosed := func(i int) error {
+		lanes[i] = nil
+		open--
+		if ctx.Err() == nil {
+			return errDeliveriesClosed
+		}
+		return nil
 	}
 
-	account.Balance = account.Balance.Add(m.Amount)
-	account.UpdatedAt = time.Now()
+	for open > 0 {
+		if ctx.Err() != nil {
+			return nil
+		}
+		progressed := false
+		for i := range lanes {
+		take:
+			for n := 0; n < c.lanes[i].Weight && lanes[i] != nil; n++ {
+				select {
+				case d, ok := <-lanes[i]:
+					if !ok {
+						if err := laneClosed(i); err != nil {
+							return err
+						}
+						break take
+					}
+					dispatch(d)
+					progressed = true
+				default:
+					break take
+				}
+			}
+		}
+		if progressed || open == 0 {
+			continue
+		}
 
-	if err := c.accountRepo.UpdateBalanceTx(account); err != nil {
-		c.logger.Printf("worker: UpdateBalanceTx error: %v", err)
-		_ = c.transactionRepo.UpdateStatus(m.TransactionID, "failed_update_balance")
-		_ = d.Nack(false, true)
-		return err
+		// Nothing ready anywhere: block until some lane delivers or closes, or
+		// ctx is done. Case 0 is ctx.
+		cases := make([]reflect.SelectCase, 1, open+1)
+		cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+		index := make([]int, 1, open+1)
+		for i, ch := range lanes {
+			if ch != nil {
+				cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)})
+				index = append(index, i)
+			}
+		}
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return nil
+		}
+		if !ok {
+			if err := laneClosed(index[chosen]); err != nil {
+				return err
+			}
+			continue
+		}
+		dispatch(v.Interface().(messaging.Delivery))
+	}
+	return nil
+}
+
+func (c *Consumer) describeLanes() string {
+	parts := make([]string, len(c.lanes))
+	for i, lane := range c.lanes {
+		parts[i] = lane.Queue
+		if len(c.lanes) > 1 {
+			parts[i] += fmt.Sprintf("(x%d)", lane.Weight)
+		}
 	}
+	return strings.Join(parts, ", ")
+}
 
-	if err := c.transactionRepo.UpdateStatus(m.TransactionID, "completed"); err != nil {
-		c.logger.Printf("worker: warning: failed to mark trx completed: %v", err)
-		_ = d.Ack(false)
-		return err
+// shardFor picks the worker for a delivery by its shard key
+func (c *Consumer) shardFor(d messaging.Delivery) int {
+	if c.opts.Workers == 1 {
+		return 0
 	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(c.opts.ShardKey(d)))
+	return int(h.Sum32() % uint32(c.opts.Workers))
+}
 
-	_ = d.Ack(false)
-	c.logger.Printf("worker: processed tx=%s acc=%s amount=%s", m.TransactionID, m.AccountNumber, m.Amount.String())
-	return nil
+// settle acks or nacks d as the handler decided
+func (c *Consumer) settle(d messaging.Delivery, outcome Outcome) {
+	var err error
+	switch outcome {
+	case Ack:
+		err = c.broker.Ack(d)
+	case Retry:
+		if err = c.broker.Nack(d, true); err == nil {
+			c.logger.Printf("worker: message %s handed back for retry (attempt %d)", d.ID, d.Attempts+1)
+		}
+	default:
+		err = c.broker.Nack(d, false)
+	}
+	if err != nil {
+		c.logger.Printf("worker: settle message %s (%s) error: %v", d.ID, outcome, err)
+	}
 }
@@ -0,0 +1,120 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
+)
+
+var ErrNoHandler = errors.New("worker: no handler for message")
+
+// Outcome tells the consumer how to settle a delivery
+type Outcome int
+
+const (
+	// Ack removes the message from the queue
+	Ack Outcome = iota
+	// Retry hands the message back to the broker, which delays it or parks it
+	// once the queue's attempts are used up
+	Retry
+	// Reject parks the message straight away
+	Reject
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case Ack:
+		return "ack"
+	case Retry:
+		return "retry"
+	case Reject:
+		return "reject"
+	default:
+		return fmt.Sprintf("outcome(%d)", int(o))
+	}
+}
+
+// Handler processes one delivery. The error is reported by the middleware;
+// the outcome alone decides how the delivery is settled, so a handler may
+// ack a message it could not process (e.g. its account no longer exists).
+type Handler func(ctx context.Context, d messaging.Delivery) (Outcome, error)
+
+// Middleware wraps a handler with cross-cutting behaviour
+type Middleware func(next Handler) Handler
+
+// Mux routes deliveries to handlers by envelope type, falling back to the
+// routing key for bodies that are not a known envelope
+type Mux struct {
+	mu         sync.RWMutex
+	byType     map[string]Handler
+	byKey      map[string]Handler
+	middleware []Middleware
+}
+
+func NewMux() *Mux {
+	return &Mux{
+		byType: make(map[string]Handler),
+		byKey:  make(map[string]Handler),
+	}
+}
+
+// Use appends middleware; the first one added is the outermost
+func (m *Mux) Use(mw ...Middleware) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.middleware = append(m.middleware, mw...)
+}
+
+// HandleType registers h for an envelope type, e.g. contract.TypeTopUpCreated
+func (m *Mux) HandleType(msgType string, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byType[msgType] = h
+}
+
+// HandleRoutingKey registers h for messages published with routingKey
+func (m *Mux) HandleRoutingKey(routingKey string, h Handler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.byKey[routingKey] = h
+}
+
+// Handle dispatches d through the middleware chain. Messages no handler
+// claims are rejected with ErrNoHandler.
+func (m *Mux) Handle(ctx context.Context, d messaging.Delivery) (Outcome, error) {
+	m.mu.RLock()
+	h := m.route(d)
+	chain := m.middleware
+	m.mu.RUnlock()
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		h = chain[i](h)
+	}
+	return h(ctx, d)
+}
+
+func (m *Mux) route(d messaging.Delivery) Handler {
+	if env, err := contract.Decode(d.Body); err == nil {
+		if h, ok := m.byType[env.Type]; ok {
+			return h
+		}
+	}
+	if h, ok := m.byKey[d.RoutingKey]; ok {
+		return h
+	}
+	return func(context.Context, messaging.Delivery) (Outcome, error) {
+		return Reject, fmt.Errorf("%w (routing key %q)", ErrNoHandler, d.RoutingKey)
+	}
+}
+
+// MessageType is the envelope type of d, or "" when it is not a known envelope
+func MessageType(d messaging.Delivery) string {
+	if env, err := contract.Decode(d.Body); err == nil {
+		return env.Type
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+// Logging reports failed and unacked deliveries
+func Logging(logger *log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, d messaging.Delivery) (Outcome, error) {
+			outcome, err := next(ctx, d)
+			if err != nil || outcome != Ack {
+				logger.Printf("worker: message %s type=%q key=%q attempt=%d -> %s: %v", d.ID, MessageType(d), d.RoutingKey, d.Attempts+1, outcome, err)
+			}
+			return outcome, err
+		}
+	}
+}
+
+// Recover turns a panicking handler into a rejected delivery so one bad
+// message cannot take the worker down; the message stays replayable from
+// the parking queue
+func Recover(logger *log.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, d messaging.Delivery) (outcome Outcome, err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					logger.Printf("worker: panic handling message %s: %v\n%s", d.ID, p, debug.Stack())
+					outcome, err = Reject, fmt.Errorf("worker: handler panic: %v", p)
+				}
+			}()
+			return next(ctx, d)
+		}
+	}
+}
+
+// Metrics records each delivery's outcome, handling time and lag, the time
+// the message waited between being published and being handled
+type Metrics interface {
+	Observe(msgType string, outcome Outcome, elapsed, lag time.Duration)
+}
+
+// Instrument reports every delivery to m
+func Instrument(m Metrics) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, d messaging.Delivery) (Outcome, error) {
+			start := time.Now()
+			var lag time.Duration
+			if !d.Timestamp.IsZero() && start.After(d.Timestamp) {
+				lag = start.Sub(d.Timestamp)
+			}
+			outcome, err := next(ctx, d)
+			m.Observe(MessageType(d), outcome, time.Since(start), lag)
+			return outcome, err
+		}
+	}
+}
+
+// ExpvarMetrics publishes counters under the "worker" expvar, served at
+// /debug/vars: "<type>.<outcome>" counts deliveries, "<type>.ms" sums
+// handling time in milliseconds and "<type>.lag_ms" is the lag of the latest
+// delivery
+type ExpvarMetrics struct {
+	vars *expvar.Map
+}
+
+var (
+	expvarOnce sync.Once
+	expvarMap  *expvar.Map
+)
+
+func NewExpvarMetrics() *ExpvarMetrics {
+	expvarOnce.Do(func() {
+		expvarMap = expvar.NewMap("worker")
+	})
+	return &ExpvarMetrics{vars: expvarMap}
+}
+
+func (m *ExpvarMetrics) Observe(msgType string, outcome Outcome, elapsed, lag time.Duration) {
+	if msgType == "" {
+		msgType = "unknown"
+	}
+	m.vars.Add(msgType+"."+outcome.String(), 1)
+	m.vars.Add(msgType+".ms", elapsed.Milliseconds())
+
+	gauge := new(expvar.Int)
+	gauge.Set(lag.Milliseconds())
+	m.vars.Set(msgType+".lag_ms", gauge)
+}
+
+// IdempotencyStore remembers which message IDs have been settled
+type IdempotencyStore interface {
+	Seen(messageID string) (bool, error)
+	Remember(messageID string) error
+}
+
+// Idempotent acks redelivered duplicates of messages that were already acked
+// without calling the handler again. Store errors fall through to the
+// handler, which must then be idempotent on its own.
+func Idempotent(store IdempotencyStore) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, d messaging.Delivery) (Outcome, error) {
+			if d.ID == "" {
+				return next(ctx, d)
+			}
+			if seen, err := store.Seen(d.ID); err == nil && seen {
+				return Ack, nil
+			}
+			outcome, err := next(ctx, d)
+			if outcome == Ack {
+				_ = store.Remember(d.ID)
+			}
+			return outcome, err
+		}
+	}
+}
+
+// MemoryIdempotencyStore keeps the most recent message IDs in memory. It only
+// catches duplicates seen by this process.
+type MemoryIdempotencyStore struct {
+	mu    sync.Mutex
+	size  int
+	seen  map[string]struct{}
+	order []string
+}
+
+// NewMemoryIdempotencyStore remembers up to size IDs (default 10000)
+func NewMemoryIdempotencyStore(size int) *MemoryIdempotencyStore {
+	if size <= 0 {
+		size = 10000
+	}
+	return &MemoryIdempotencyStore{size: size, seen: make(map[string]struct{}, size)}
+}
+
+func (s *MemoryIdempotencyStore) Seen(messageID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.seen[messageID]
+	return ok, nil
+}
+
+func (s *MemoryIdempotencyStore) Remember(messageID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.seen[messageID]; ok {
+		return nil
+	}
+	if len(s.order) == s.size {
+		delete(s.seen, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.seen[messageID] = struct{}{}
+	s.order = append(s.order, messageID)
+	return nil
+}
@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+)
+
+// RPC error codes carried in RPCReply.Error
+const (
+	RPCCodeBadRequest    = "bad_request"
+	RPCCodeUnknownMethod = "unknown_method"
+	RPCCodeNotFound      = "not_found"
+	RPCCodeInternal      = "internal"
+)
+
+// RPCRequest is the body of a request on the RPC queue. The caller sets the
+// reply-to queue and a correlation ID on the message.
+type RPCRequest struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// RPCReply is sent to the request's reply-to queue with its correlation ID;
+// exactly one of Result and Error is set
+type RPCReply struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is returned by RPC handlers to answer with a specific code; any
+// other error is answered as RPCCodeInternal
+type RPCError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// RPCHandler answers one method. Its result is encoded as JSON into the reply.
+type RPCHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// RPCServer answers requests from other services over the broker. Register a
+// handler per method, then consume the RPC queue with Handle.
+type RPCServer struct {
+	broker messaging.BrokerPort
+	logger *log.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]RPCHandler
+}
+
+func NewRPCServer(broker messaging.BrokerPort, logger *log.Logger) *RPCServer {
+	return &RPCServer{broker: broker, logger: logger, handlers: map[string]RPCHandler{}}
+}
+
+// Register serves method with h, replacing any earlier handler
+func (s *RPCServer) Register(method string, h RPCHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[method] = h
+}
+
+// Handle is a Handler for the RPC queue. Requests without a reply-to queue
+// are rejected; a reply that cannot be sent is retried, since handlers only
+// read.
+func (s *RPCServer) Handle(ctx context.Context, d messaging.Delivery) (Outcome, error) {
+	if d.ReplyTo == "" {
+		return Reject, fmt.Errorf("rpc request %s has no reply-to", d.ID)
+	}
+
+	reply := s.dispatch(ctx, d.Body)
+	body, err := json.Marshal(reply)
+	if err != nil {
+		return Reject, fmt.Errorf("encode rpc reply: %w", err)
+	}
+
+	// Replies are not mandatory: a caller that timed out has gone away
+	if err := s.broker.Publish(ctx, "", d.ReplyTo, messaging.Message{
+		ContentType:   "application/json",
+		CorrelationID: d.CorrelationID,
+		Body:          body,
+	}); err != nil {
+		return Retry, fmt.Errorf("send rpc reply: %w", err)
+	}
+	return Ack, nil
+}
+
+func (s *RPCServer) dispatch(ctx context.Context, body []byte) RPCReply {
+	var req RPCRequest
+	if err := json.Unmarshal(body, &req); err != nil || req.Method == "" {
+		return RPCReply{Error: &RPCError{Code: RPCCodeBadRequest, Message: "body must be {\"method\": ..., \"params\": ...}"}}
+	}
+
+	s.mu.RLock()
+	h, ok := s.handlers[req.Method]
+	s.mu.RUnlock()
+	if !ok {
+		return RPCReply{Error: &RPCError{Code: RPCCodeUnknownMethod, Message: req.Method}}
+	}
+
+	result, err := h(ctx, req.Params)
+	if err != nil {
+		var rpcErr *RPCError
+		if errors.As(err, &rpcErr) {
+			return RPCReply{Error: rpcErr}
+		}
+		s.logger.Printf("rpc %s: %v", req.Method, err)
+		return RPCReply{Error: &RPCError{Code: RPCCodeInternal, Message: "internal error"}}
+	}
+
+	raw, err := json.Marshal(result)
+	if err != nil {
+		s.logger.Printf("rpc %s: encode result: %v", req.Method, err)
+		return RPCReply{Error: &RPCError{Code: RPCCodeInternal, Message: "internal error"}}
+	}
+	return RPCReply{Result: raw}
+}
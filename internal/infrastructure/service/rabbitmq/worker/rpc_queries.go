@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+	"github.com/shopspring/decimal"
+)
+
+// Methods served by the RPC queue
+const (
+	RPCMethodBalance           = "balance.get"
+	RPCMethodTransactionStatus = "transaction.status"
+)
+
+type BalanceParams struct {
+	AccountNumber string `json:"accountNumber"`
+}
+
+type BalanceResult struct {
+	AccountNumber string          `json:"accountNumber"`
+	Balance       decimal.Decimal `json:"balance"`
+	Currency      string          `json:"currency"`
+	Status        string          `json:"status"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
+
+type TransactionStatusParams struct {
+	TransactionID string `json:"transactionId"`
+}
+
+type TransactionStatusResult struct {
+	TransactionID string          `json:"transactionId"`
+	Type          string          `json:"type"`
+	Status        string          `json:"status"`
+	Amount        decimal.Decimal `json:"amount"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
+
+// BalanceQuery answers balance.get with the current balance of an account
+func BalanceQuery(accountRepo repository.AccountRepository) RPCHandler {
+	return func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		var p BalanceParams
+		if err := json.Unmarshal(raw, &p); err != nil || p.AccountNumber == "" {
+			return nil, &RPCError{Code: RPCCodeBadRequest, Message: "accountNumber is required"}
+		}
+
+		account, err := accountRepo.GetByAccountNumber(p.AccountNumber)
+		if err != nil {
+			return nil, err
+		}
+		if account == nil {
+			return nil, &RPCError{Code: RPCCodeNotFound, Message: "account not found"}
+		}
+		return BalanceResult{
+			AccountNumber: account.AccountNumber,
+			Balance:       account.Balance,
+			Currency:      account.Currency,
+			Status:        account.Status,
+			UpdatedAt:     account.UpdatedAt,
+		}, nil
+	}
+}
+
+// TransactionStatusQuery answers transaction.status for any transaction ID
+func TransactionStatusQuery(transactionRepo repository.TransactionRepository) RPCHandler {
+	return func(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+		var p TransactionStatusParams
+		if err := json.Unmarshal(raw, &p); err != nil || p.TransactionID == "" {
+			return nil, &RPCError{Code: RPCCodeBadRequest, Message: "transactionId is required"}
+		}
+
+		txn, err := transactionRepo.GetByTransactionID(p.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if txn == nil {
+			return nil, &RPCError{Code: RPCCodeNotFound, Message: "transaction not found"}
+		}
+		return TransactionStatusResult{
+			TransactionID: txn.TransactionID,
+			Type:          txn.Type,
+			Status:        txn.Status,
+			Amount:        txn.Amount,
+			UpdatedAt:     txn.UpdatedAt,
+		}, nil
+	}
+}
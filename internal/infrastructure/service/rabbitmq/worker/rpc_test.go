@@ -0,0 +1,115 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/infrastructure/service/membroker"
+)
+
+func newTestRPCServer(t *testing.T) (*RPCServer, *membroker.Broker) {
+	t.Helper()
+	broker := membroker.New()
+	t.Cleanup(func() { _ = broker.Close() })
+
+	s := NewRPCServer(broker, log.New(io.Discard, "", 0))
+	s.Register("echo", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return params, nil
+	})
+	s.Register("missing", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, &RPCError{Code: RPCCodeNotFound, Message: "no such thing"}
+	})
+	s.Register("broken", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, errors.New("database is down")
+	})
+	return s, broker
+}
+
+func rpcDelivery(body, replyTo, correlationID string) messaging.Delivery {
+	return messaging.Delivery{Message: messaging.Message{
+		ID:            "req-1",
+		Body:          []byte(body),
+		ReplyTo:       replyTo,
+		CorrelationID: correlationID,
+	}}
+}
+
+func TestRPCServerRejectsWithoutReplyTo(t *testing.T) {
+	s, broker := newTestRPCServer(t)
+
+	outcome, err := s.Handle(context.Background(), rpcDelivery(`{"method":"echo"}`, "", "c-1"))
+	if outcome != Reject || err == nil {
+		t.Fatalf("outcome = %v, err = %v; want Reject with an error", outcome, err)
+	}
+	if got := broker.Messages(""); len(got) != 0 {
+		t.Fatalf("replied without a reply-to: %+v", got)
+	}
+}
+
+func TestRPCServerReplies(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantCode  string
+		wantValue string
+	}{
+		{name: "result", body: `{"method":"echo","params":{"accountNumber":"123"}}`, wantValue: `{"accountNumber":"123"}`},
+		{name: "unknown method", body: `{"method":"account.delete"}`, wantCode: RPCCodeUnknownMethod},
+		{name: "not json", body: `balance please`, wantCode: RPCCodeBadRequest},
+		{name: "no method", body: `{"params":{}}`, wantCode: RPCCodeBadRequest},
+		{name: "handler error code", body: `{"method":"missing"}`, wantCode: RPCCodeNotFound},
+		{name: "internal error is masked", body: `{"method":"broken"}`, wantCode: RPCCodeInternal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, broker := newTestRPCServer(t)
+			// The default exchange only routes to queues that exist
+			broker.Messages("reply.q")
+
+			outcome, err := s.Handle(context.Background(), rpcDelivery(tt.body, "reply.q", "corr-42"))
+			if outcome != Ack || err != nil {
+				t.Fatalf("outcome = %v, err = %v; want Ack", outcome, err)
+			}
+
+			replies := broker.Messages("reply.q")
+			if len(replies) != 1 {
+				t.Fatalf("got %d replies, want 1", len(replies))
+			}
+			if replies[0].CorrelationID != "corr-42" {
+				t.Errorf("correlation id = %q, want corr-42", replies[0].CorrelationID)
+			}
+
+			var reply RPCReply
+			if err := json.Unmarshal(replies[0].Body, &reply); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantCode != "" {
+				if reply.Error == nil || reply.Error.Code != tt.wantCode || reply.Result != nil {
+					t.Fatalf("reply = %s, want error %s", replies[0].Body, tt.wantCode)
+				}
+				if tt.wantCode == RPCCodeInternal && reply.Error.Message != "internal error" {
+					t.Errorf("internal error leaked: %q", reply.Error.Message)
+				}
+				return
+			}
+			if reply.Error != nil || string(reply.Result) != tt.wantValue {
+				t.Fatalf("reply = %s, want result %s", replies[0].Body, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestRPCServerRetriesUnsentReply(t *testing.T) {
+	s, broker := newTestRPCServer(t)
+	_ = broker.Close()
+
+	outcome, err := s.Handle(context.Background(), rpcDelivery(`{"method":"echo"}`, "reply.q", "c-1"))
+	if outcome != Retry || err == nil {
+		t.Fatalf("outcome = %v, err = %v; want Retry with an error", outcome, err)
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/transaction"
+	"github.com/junicochandra/golang-api-service/internal/app/transaction/dto"
+)
+
+// FanoutSubscriber is implemented by the RabbitMQ service and the NATS broker
+type FanoutSubscriber interface {
+	WaitReady(ctx context.Context) error
+	SubscribeFanout(ctx context.Context, exchange string, handler func(body []byte)) error
+}
+
+// StatusRelay feeds status events from StatusExchange into the local hub.
+// Every API instance runs one, so each SSE client sees every event.
+type StatusRelay struct {
+	source FanoutSubscriber
+	hub    *transaction.StatusHub
+	logger *log.Logger
+}
+
+func NewStatusRelay(source FanoutSubscriber, hub *transaction.StatusHub, logger *log.Logger) *StatusRelay {
+	return &StatusRelay{
+		source: source,
+		hub:    hub,
+		logger: logger,
+	}
+}
+
+// Start resubscribes after errors until ctx is done
+func (s *StatusRelay) Start(ctx context.Context) {
+	for {
+		if err := s.source.WaitReady(ctx); err != nil {
+			s.logger.Printf("relay: stopping: %v", err)
+			return
+		}
+
+		err := s.source.SubscribeFanout(ctx, StatusExchange, s.handle)
+		if ctx.Err() != nil {
+			s.logger.Println("relay: context done, stopping")
+			return
+		}
+		s.logger.Printf("relay: subscription ended: %v, retrying", err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (s *StatusRelay) handle(body []byte) {
+	var evt dto.TransactionStatusEvent
+	if err := json.Unmarshal(body, &evt); err != nil {
+		s.logger.Printf("relay: invalid status event: %v", err)
+		return
+	}
+	s.hub.Publish(evt)
+}
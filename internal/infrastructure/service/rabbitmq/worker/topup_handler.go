@@ -0,0 +1,188 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/contract"
+	"github.com/junicochandra/golang-api-service/internal/app/transaction/dto"
+	"github.com/junicochandra/golang-api-service/internal/domain/entity"
+	"github.com/junicochandra/golang-api-service/internal/domain/repository"
+)
+
+// StatusExchange is the fanout exchange carrying transaction status events
+const StatusExchange = "transaction.status"
+
+// TopUpConsumer names the top-up handler in the processed_messages inbox
+const TopUpConsumer = "topup"
+
+// TopUpHandler credits accounts for topup.created messages and raises
+// transaction.completed or transaction.failed once a top-up is settled
+type TopUpHandler struct {
+	broker          messaging.BrokerPort
+	transactionRepo repository.TransactionRepository
+	accountRepo     repository.AccountRepository
+	events          messaging.EventPublisher
+	logger          *log.Logger
+}
+
+func NewTopUpHandler(broker messaging.BrokerPort, trx repository.TransactionRepository, acc repository.AccountRepository, events messaging.EventPublisher, logger *log.Logger) *TopUpHandler {
+	return &TopUpHandler{
+		broker:          broker,
+		transactionRepo: trx,
+		accountRepo:     acc,
+		events:          events,
+		logger:          logger,
+	}
+}
+
+// TopUpShardKey orders top-ups per account when used as ConsumerOptions.ShardKey.
+// Undecodable messages are rejected by whichever worker gets them.
+func TopUpShardKey(d messaging.Delivery) string {
+	if m, _, err := contract.DecodeTopUpCreated(d.Body); err == nil {
+		return m.AccountNumber
+	}
+	return ""
+}
+
+func (h *TopUpHandler) Handle(ctx context.Context, d messaging.Delivery) (Outcome, error) {
+	msg, env, err := contract.DecodeTopUpCreated(d.Body)
+	if err != nil {
+		return Reject, fmt.Errorf("invalid message: %w", err)
+	}
+	m := *msg
+
+	// Cheap early exit for redeliveries; the inbox below is what guarantees a
+	// single credit
+	trx, err := h.transactionRepo.GetByTransactionID(m.TransactionID)
+	if err != nil {
+		return Retry, fmt.Errorf("get transaction: %w", err)
+	}
+	if trx == nil {
+		return Reject, fmt.Errorf("transaction not found: %s", m.TransactionID)
+	}
+	if trx.Status == "completed" || trx.Status == "success" {
+		return Ack, nil
+	}
+	// The ledger row is authoritative; a message that disagrees with it, e.g.
+	// after an edit-and-replay, is parked rather than credited
+	if trx.ReceiverAccountID != m.AccountNumber || !trx.Amount.Equal(m.Amount) {
+		return Reject, fmt.Errorf("message does not match tx=%s: account %s amount %s", m.TransactionID, m.AccountNumber, m.Amount.String())
+	}
+
+	// Set processing
+	if err := h.setStatus(&m, "processing"); err != nil {
+		return Retry, fmt.Errorf("set processing: %w", err)
+	}
+
+	// Check the account first so a missing or blocked one gets a precise status
+	account, err := h.accountRepo.GetByAccountNumber(m.AccountNumber)
+	if err != nil {
+		_ = h.setStatus(&m, "failed_account_error")
+		return Retry, fmt.Errorf("get account: %w", err)
+	}
+	if account == nil {
+		h.fail(&m, "failed_account_not_found")
+		return Ack, fmt.Errorf("account not found: %s", m.AccountNumber)
+	}
+	// Status may have changed since the message was queued
+	if !account.CanCredit() {
+		h.fail(&m, "failed_account_"+account.Status)
+		return Ack, fmt.Errorf("account %s is %s, rejecting tx=%s", m.AccountNumber, account.Status, m.TransactionID)
+	}
+
+	// Credit, mark completed and record the message in one DB transaction
+	inbox := &entity.ProcessedMessage{
+		Consumer:    TopUpConsumer,
+		MessageID:   inboxKey(d, env, &m),
+		ProcessedAt: time.Now(),
+	}
+	err = h.accountRepo.CreditTx(m.AccountNumber, m.Amount, m.TransactionID, inbox)
+	switch {
+	case errors.Is(err, repository.ErrAlreadyProcessed):
+		// A concurrent duplicate committed first; undo our "processing"
+		_ = h.setStatus(&m, "completed")
+		return Ack, nil
+	case errors.Is(err, repository.ErrAccountUnavailable):
+		h.fail(&m, "failed_account_unavailable")
+		return Ack, fmt.Errorf("account %s refused the credit, rejecting tx=%s", m.AccountNumber, m.TransactionID)
+	case err != nil:
+		_ = h.setStatus(&m, "failed_update_balance")
+		return Retry, fmt.Errorf("credit account: %w", err)
+	}
+
+	h.announce(&m, "completed")
+	h.events.Publish(contract.TypeTransactionCompleted, &contract.TransactionCompleted{
+		TransactionID: m.TransactionID,
+		Type:          "topup",
+		AccountNumber: m.AccountNumber,
+		Amount:        m.Amount,
+		Currency:      m.Currency,
+		Reference:     m.Reference,
+		CompletedAt:   time.Now(),
+	}, m.TransactionID)
+
+	h.logger.Printf("worker: processed tx=%s acc=%s amount=%s", m.TransactionID, m.AccountNumber, m.Amount.String())
+	return Ack, nil
+}
+
+// fail records a final failure status and raises transaction.failed
+func (h *TopUpHandler) fail(m *contract.TopUpCreated, status string) {
+	if err := h.setStatus(m, status); err != nil {
+		h.logger.Printf("worker: set %s for tx=%s: %v", status, m.TransactionID, err)
+	}
+	h.events.Publish(contract.TypeTransactionFailed, &contract.TransactionFailed{
+		TransactionID: m.TransactionID,
+		Type:          "topup",
+		AccountNumber: m.AccountNumber,
+		Amount:        m.Amount,
+		Currency:      m.Currency,
+		Reason:        status,
+		FailedAt:      time.Now(),
+	}, m.TransactionID)
+}
+
+// setStatus updates the transaction and announces the change on StatusExchange
+func (h *TopUpHandler) setStatus(m *contract.TopUpCreated, status string) error {
+	if err := h.transactionRepo.UpdateStatus(m.TransactionID, status); err != nil {
+		return err
+	}
+	h.announce(m, status)
+	return nil
+}
+
+// announce publishes a status event. Best effort: a missed event only delays
+// the client until it reconnects.
+func (h *TopUpHandler) announce(m *contract.TopUpCreated, status string) {
+	body, err := json.Marshal(dto.TransactionStatusEvent{
+		TransactionID: m.TransactionID,
+		AccountNumber: m.AccountNumber,
+		Amount:        m.Amount,
+		Status:        status,
+		OccurredAt:    time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	if err := h.broker.Publish(context.Background(), StatusExchange, "", messaging.Message{ContentType: "application/json", Body: body}); err != nil {
+		h.logger.Printf("worker: publish status event error: %v", err)
+	}
+}
+
+// inboxKey identifies a message across redeliveries and replays. Bare legacy
+// messages carry no id, so their transaction stands in.
+func inboxKey(d messaging.Delivery, env *contract.Envelope, m *contract.TopUpCreated) string {
+	switch {
+	case env.MessageID != "":
+		return env.MessageID
+	case d.ID != "":
+		return d.ID
+	default:
+		return "tx:" + m.TransactionID
+	}
+}
@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DailyJob runs fn once a day at the given offset after local midnight
+type DailyJob struct {
+	name   string
+	offset time.Duration
+	fn     func(now time.Time) error
+	logger *log.Logger
+}
+
+func NewDailyJob(name string, offset time.Duration, fn func(now time.Time) error, logger *log.Logger) *DailyJob {
+	return &DailyJob{
+		name:   name,
+		offset: offset,
+		fn:     fn,
+		logger: logger,
+	}
+}
+
+// Start blocks until ctx is done
+func (j *DailyJob) Start(ctx context.Context) {
+	for {
+		next := j.next(time.Now())
+		j.logger.Printf("scheduler: %s next run at %s", j.name, next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			j.logger.Printf("scheduler: %s stopped", j.name)
+			return
+		case now := <-timer.C:
+			if err := j.fn(now); err != nil {
+				j.logger.Printf("scheduler: %s error: %v", j.name, err)
+			}
+		}
+	}
+}
+
+func (j *DailyJob) next(now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	next := midnight.Add(j.offset)
+	if !next.After(now) {
+		next = midnight.AddDate(0, 0, 1).Add(j.offset)
+	}
+	return next
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware allows only the emails listed in ADMIN_EMAILS (comma
+// separated). It must run after AuthMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	admins := map[string]bool{}
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(strings.ToLower(email)); email != "" {
+			admins[email] = true
+		}
+	}
+
+	return func(c *gin.Context) {
+		email, _ := c.Get("email")
+		s, _ := email.(string)
+
+		if !admins[strings.ToLower(s)] {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
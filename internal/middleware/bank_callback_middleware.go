@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BankCallbackMiddleware checks the shared secret banks send in X-Callback-Token.
+// Callbacks are refused when VA_CALLBACK_TOKEN is not configured.
+func BankCallbackMiddleware() gin.HandlerFunc {
+	secret := []byte(os.Getenv("VA_CALLBACK_TOKEN"))
+
+	return func(c *gin.Context) {
+		token := []byte(c.GetHeader("X-Callback-Token"))
+
+		if len(secret) == 0 || subtle.ConstantTimeCompare(token, secret) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid callback token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -1,43 +1,111 @@
 package router
 
 import (
+	"expvar"
+	"log"
+	"os"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 
+	"github.com/junicochandra/golang-api-service/internal/app/account"
 	"github.com/junicochandra/golang-api-service/internal/app/auth"
+	"github.com/junicochandra/golang-api-service/internal/app/balance"
+	"github.com/junicochandra/golang-api-service/internal/app/deadletter"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging"
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/seal"
 	"github.com/junicochandra/golang-api-service/internal/app/payment"
+	"github.com/junicochandra/golang-api-service/internal/app/qris"
+	"github.com/junicochandra/golang-api-service/internal/app/transaction"
 	"github.com/junicochandra/golang-api-service/internal/app/user"
+	"github.com/junicochandra/golang-api-service/internal/app/virtualaccount"
 	"github.com/junicochandra/golang-api-service/internal/handler"
 	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
 	"github.com/junicochandra/golang-api-service/internal/infrastructure/repository"
-	"github.com/junicochandra/golang-api-service/internal/infrastructure/service/rabbitmq"
 	"github.com/junicochandra/golang-api-service/internal/middleware"
+	"github.com/shopspring/decimal"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func SetupRouter(rabbitSvc *rabbitmq.RabbitMQService) *gin.Engine {
+// Config holds the routing settings bootstrap reads from the environment, so
+// a bad value stops startup before any route is served
+type Config struct {
+	// PriorityAmount sends top-ups of at least this much to the priority lane;
+	// zero disables the amount rule
+	PriorityAmount decimal.Decimal
+	PriorityTiers  []string
+	Backpressure   payment.BackpressureConfig
+}
+
+// SetupRouter wires the repositories, use cases and handlers and registers
+// every route. topUpQueues maps each top-up lane to its queue for the
+// backpressure check; sealer opens sealed dead letters for DLQ_DECRYPT_EMAILS
+// and may be nil.
+func SetupRouter(broker messaging.BrokerPort, statusHub *transaction.StatusHub, deadLetters messaging.DeadLetterPort, deadLetterQueues map[string]string, topUpQueues map[string]string, sealer *seal.Sealer, cfg Config) *gin.Engine {
 	r := gin.Default()
 
 	// Swagger
 	r.GET("/api/doc/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Runtime and worker counters; memstats and the cmdline are for admins only
+	r.GET("/debug/vars", middleware.AuthMiddleware(), middleware.AdminMiddleware(), gin.WrapH(expvar.Handler()))
+
 	// Dependency Injection
 	userRepository := repository.NewUserRepository(database.DB)
 	accountRepository := repository.NewAccountRepository(database.DB)
 	transactionRepository := repository.NewTransactionRepository(database.DB)
+	snapshotRepository := repository.NewBalanceSnapshotRepository(database.DB)
+	vaRepository := repository.NewVirtualAccountRepository(database.DB)
+	qrisCodeRepository := repository.NewQRISCodeRepository(database.DB)
+	deadLetterAuditRepository := repository.NewDeadLetterAuditRepository(database.DB)
 
-	userUC := user.NewUserUseCase(userRepository)
+	events := messaging.NewEventPublisher(broker, log.New(os.Stdout, "[events] ", log.LstdFlags))
+
+	userUC := user.NewUserUseCase(userRepository, events)
 	userHandler := handler.NewUserHandler(userUC)
 
-	authUC := auth.NewAuthUseCase(userRepository)
+	authUC := auth.NewAuthUseCase(userRepository, events)
 	authHandler := handler.NewAuthHandler(authUC)
 
-	topUpUC := payment.NewTopUpUseCase(accountRepository, transactionRepository, rabbitSvc)
+	accountUC := account.NewAccountUseCase(accountRepository)
+	accountHandler := handler.NewAccountHandler(accountUC)
+
+	balanceUC := balance.NewBalanceUseCase(userRepository, accountRepository, transactionRepository, snapshotRepository)
+	balanceHandler := handler.NewBalanceHandler(balanceUC)
+
+	transactionUC := transaction.NewTransactionStatusUseCase(userRepository, accountRepository, transactionRepository, statusHub)
+	transactionHandler := handler.NewTransactionHandler(transactionUC)
+
+	qrisUC := qris.NewQRISUseCase(userRepository, accountRepository, qrisCodeRepository)
+	qrisHandler := handler.NewQRISHandler(qrisUC)
+
+	priorityPolicy := payment.NewThresholdPolicy(cfg.PriorityAmount, cfg.PriorityTiers)
+	var backpressure *payment.Backpressure
+	if inspector, ok := broker.(messaging.QueueInspector); ok {
+		backpressure = payment.NewBackpressure(inspector, topUpQueues, cfg.Backpressure)
+	}
+	topUpUC := payment.NewTopUpUseCase(accountRepository, transactionRepository, broker, priorityPolicy, backpressure)
 	topUpHandler := handler.NewPaymentHandler(topUpUC)
 
+	vaUC := virtualaccount.NewVirtualAccountUseCase(vaRepository, userRepository, accountRepository, transactionRepository, topUpUC, os.Getenv("VA_SIMULATOR") == "true")
+	vaHandler := handler.NewVirtualAccountHandler(vaUC)
+
+	deadLetterUC := deadletter.NewDeadLetterUseCase(deadLetters, deadLetterAuditRepository, deadLetterQueues, sealer, strings.Split(os.Getenv("DLQ_DECRYPT_EMAILS"), ","))
+	deadLetterHandler := handler.NewDeadLetterHandler(deadLetterUC)
+
+	healthHandler := handler.NewHealthHandler(broker)
+
 	// Routes
 	api := r.Group("/api/v1")
 	{
+		// Health
+		health := api.Group("/health")
+		{
+			health.GET("/live", healthHandler.Live)
+			health.GET("/ready", healthHandler.Ready)
+		}
+
 		// Auth
 		auth := api.Group("/auth")
 		{
@@ -61,12 +129,63 @@ func SetupRouter(rabbitSvc *rabbitmq.RabbitMQService) *gin.Engine {
 			pay.POST("/topup", topUpHandler.CreateTopUp)
 		}
 
+		// QRIS
+		qr := api.Group("/qris")
+		{
+			qr.POST("/parse", qrisHandler.Parse)
+			qr.GET("/png", qrisHandler.RenderPNG)
+		}
+
+		// Virtual account bank feed
+		va := api.Group("/virtual-accounts")
+		{
+			va.POST("/callbacks/credit", middleware.BankCallbackMiddleware(), vaHandler.CreditCallback)
+		}
+
 		// Protected Routes (JWT Required)
 		protected := api.Group("")
 		protected.Use(middleware.AuthMiddleware())
 		{
 			protected.GET("/profile", handler.Profile)
 			protected.POST("/auth/logout", authHandler.Logout)
+
+			// Account lifecycle
+			accounts := protected.Group("/accounts/:accountNumber")
+			{
+				// Status changes are for operators, not account holders
+				lifecycle := accounts.Group("", middleware.AdminMiddleware())
+				{
+					lifecycle.POST("/freeze", accountHandler.Freeze)
+					lifecycle.POST("/block", accountHandler.Block)
+					lifecycle.POST("/close", accountHandler.Close)
+					lifecycle.POST("/activate", accountHandler.Activate)
+				}
+				accounts.GET("/balance", balanceHandler.GetBalance)
+			}
+
+			// QRIS (merchant and payer)
+			protected.POST("/qris/generate", qrisHandler.Generate)
+			protected.POST("/qris/pay", qrisHandler.Pay)
+
+			// Virtual accounts
+			protected.POST("/virtual-accounts", vaHandler.Issue)
+			protected.GET("/virtual-accounts", vaHandler.List)
+			protected.GET("/virtual-accounts/:vaNumber", vaHandler.Lookup)
+			protected.DELETE("/virtual-accounts/:vaNumber", vaHandler.Deactivate)
+			protected.POST("/virtual-accounts/simulate/credit", middleware.AdminMiddleware(), vaHandler.SimulateCredit)
+
+			// Transaction status streams (SSE)
+			protected.GET("/transactions/events", transactionHandler.StreamAccounts)
+			protected.GET("/transactions/:transactionId/events", transactionHandler.StreamTransaction)
+
+			// Admin: dead-letter queues
+			admin := protected.Group("/admin", middleware.AdminMiddleware())
+			{
+				admin.GET("/dead-letters/:queue", deadLetterHandler.List)
+				admin.POST("/dead-letters/:queue/replay", deadLetterHandler.Replay)
+				admin.POST("/dead-letters/:queue/purge", deadLetterHandler.Purge)
+				admin.POST("/dead-letters/:queue/:messageId/replay", deadLetterHandler.EditAndReplay)
+			}
 		}
 	}
 	return r
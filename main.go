@@ -2,13 +2,12 @@ package main
 
 import (
 	"log"
+	"os"
 
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/junicochandra/golang-api-service/docs"
 
 	"github.com/junicochandra/golang-api-service/internal/bootstrap"
-	"github.com/junicochandra/golang-api-service/internal/domain/entity"
-	"github.com/junicochandra/golang-api-service/internal/infrastructure/config/database"
 )
 
 // @Title Golang API Service
@@ -24,12 +23,13 @@ import (
 // @In header
 // @Name Authorization
 func main() {
-	// DB Connection
-	database.Connect()
-	db := database.DB
-	if err := db.AutoMigrate(&entity.User{}); err != nil {
-		log.Fatal("Failed to migrate database: ", err)
+	// Usage: go run main.go [serve|worker|migrate|all], default all
+	mode := bootstrap.ModeAll
+	if len(os.Args) > 1 {
+		mode = os.Args[1]
 	}
 
-	bootstrap.Run()
+	if err := bootstrap.Run(mode); err != nil {
+		log.Fatal(err)
+	}
 }
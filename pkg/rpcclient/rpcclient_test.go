@@ -0,0 +1,113 @@
+package rpcclient
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/junicochandra/golang-api-service/internal/app/messaging/seal"
+)
+
+func TestNewNeedsSigningKey(t *testing.T) {
+	if _, err := New(nil, Options{}); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("err = %v, want ErrNoSigningKey", err)
+	}
+	if _, err := Dial("amqp://localhost:1/", Options{SigningKeyID: "billing"}); !errors.Is(err, ErrNoSigningKey) {
+		t.Fatalf("err = %v, want ErrNoSigningKey", err)
+	}
+}
+
+func TestEncodeSealsRequests(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encKey := make([]byte, 32)
+	if _, err := rand.Read(encKey); err != nil {
+		t.Fatal(err)
+	}
+
+	sealer, err := newSealer(Options{SigningKeyID: "billing", SigningKey: priv, EncryptionKeyID: "k1", EncryptionKey: encKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{sealer: sealer}
+	body, err := c.encode("balance.get", map[string]string{"accountNumber": "1234567890"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The server trusts the caller's public key and shares the encryption key
+	server, err := seal.New(seal.Options{
+		EncryptionKeys: map[string][]byte{"k1": encKey},
+		ActiveKeyID:    "k1",
+		VerifyKeys:     map[string]ed25519.PublicKey{"billing": priv.Public().(ed25519.PublicKey)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, info, err := server.Open(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Signed || info.SignerKeyID != "billing" || !info.Encrypted {
+		t.Errorf("info = %+v, want signed by billing and encrypted", info)
+	}
+	var req struct {
+		Method string            `json:"method"`
+		Params map[string]string `json:"params"`
+	}
+	if err := json.Unmarshal(plain, &req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Method != "balance.get" || req.Params["accountNumber"] != "1234567890" {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	var bal Balance
+	if err := decode([]byte(`{"result":{"accountNumber":"123","balance":"1500.50","currency":"IDR"}}`), &bal); err != nil {
+		t.Fatal(err)
+	}
+	if bal.AccountNumber != "123" || bal.Balance.String() != "1500.5" || bal.Currency != "IDR" {
+		t.Errorf("balance = %+v", bal)
+	}
+
+	err := decode([]byte(`{"error":{"code":"not_found","message":"account not found"}}`), &bal)
+	var rpcErr *Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != "not_found" {
+		t.Errorf("err = %v, want a not_found Error", err)
+	}
+
+	for _, body := range []string{`not json`, `{"result":"a string"}`} {
+		if err := decode([]byte(body), &bal); !errors.Is(err, ErrBadResponse) {
+			t.Errorf("decode(%s) err = %v, want ErrBadResponse", body, err)
+		}
+	}
+
+	if err := decode([]byte(`{"result":{}}`), nil); err != nil {
+		t.Errorf("nil result: %v", err)
+	}
+}
+
+func TestSettleDeliversOnce(t *testing.T) {
+	c := &Client{pending: map[string]chan outcome{}, done: make(chan struct{})}
+	wait := make(chan outcome, 1)
+	c.pending["c-1"] = wait
+
+	c.settle("c-1", outcome{body: []byte("first")})
+	c.settle("c-1", outcome{body: []byte("duplicate")})
+	c.settle("unknown", outcome{body: []byte("stray")})
+
+	if got := <-wait; string(got.body) != "first" {
+		t.Errorf("got %q, want the first reply", got.body)
+	}
+	select {
+	case got := <-wait:
+		t.Errorf("duplicate reply delivered: %q", got.body)
+	default:
+	}
+}